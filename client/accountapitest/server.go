@@ -0,0 +1,529 @@
+// Package accountapitest provides an in-process fake of the Accounts API, to test the consumers of the client without
+// running the docker-compose services.
+//
+// The fake Server holds the accounts in memory and implements the create, fetch, list (with paging and filters),
+// update and delete operations, along with the 400, 404 and 409 semantics and the error bodies of the Accounts API.
+// Faults and latency can be injected to exercise the error handling of the consumers:
+//
+//	s := accountapitest.NewServer()
+//	defer s.Close()
+//	s.InjectFault(accountapitest.Fault{Method: http.MethodGet, Status: http.StatusServiceUnavailable, Times: 1})
+//
+//	c, err := client.New(s.URL)
+//	...
+package accountapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"gitlab.com/kitolabs-private/form3/interview-accountapi/client"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// accountsPath is the path of the accounts collection. The path of an account is followed by its ID.
+const accountsPath = "/v1/organisation/accounts"
+
+// defaultPageSize is the page size used when none is requested, as in the Accounts API.
+const defaultPageSize = 100
+
+// Server is a fake Accounts API, listening on a local loopback address. Its methods are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	accounts     map[string]client.Account
+	order        []string // the account IDs in creation order
+	faults       []*Fault
+	interceptors []Interceptor
+	latency      time.Duration
+	requests     int
+
+	// now returns the creation and modification time of the accounts.
+	now func() time.Time
+}
+
+// Fault represents an error response injected in place of the regular ones.
+type Fault struct {
+	// Method restricts the fault to the requests with this HTTP method. Any request matches when empty.
+	Method string
+
+	// Status is the status code of the response, served with an error body.
+	Status int
+
+	// RetryAfter, if positive, is sent in the Retry-After header of the response, in seconds.
+	RetryAfter time.Duration
+
+	// Times is the number of matching requests which get the fault. It applies to every request when zero.
+	Times int
+}
+
+// Interceptor is called before serving every request. It returns true if it wrote the response itself, in which case
+// the request is not served by the fake.
+type Interceptor func(rw http.ResponseWriter, req *http.Request) bool
+
+// errorBody represents the error body of the responses, as returned by the Accounts API.
+type errorBody struct {
+	ErrorCode    string `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// NewServer starts and returns a new Server holding the given accounts. Call Close() when done.
+func NewServer(accounts ...client.Account) *Server {
+	s := NewUnstartedServer(accounts...)
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer returns a new Server holding the given accounts, but does not start it. Call Start() or
+// StartTLS() to start it, and Close() when done.
+func NewUnstartedServer(accounts ...client.Account) *Server {
+	s := &Server{
+		accounts: make(map[string]client.Account),
+		now:      time.Now,
+	}
+	s.Seed(accounts...)
+	s.Server = httptest.NewUnstartedServer(s)
+	return s
+}
+
+// Seed stores the given accounts as they are, replacing any account with the same ID.
+func (s *Server) Seed(accounts ...client.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range accounts {
+		s.store(a)
+	}
+}
+
+// Accounts returns the stored accounts, in creation order.
+func (s *Server) Accounts() []client.Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts := make([]client.Account, 0, len(s.order))
+	for _, id := range s.order {
+		accounts = append(accounts, s.accounts[id])
+	}
+	return accounts
+}
+
+// Account returns the stored account with the given ID, if any.
+func (s *Server) Account(id string) (client.Account, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[id]
+	return a, ok
+}
+
+// Requests returns the number of requests received so far, including the faulty ones.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// SetLatency delays every response by the given duration d, unless the request is cancelled earlier.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// InjectFault injects the given fault f. The faults are matched in the order they were injected.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all the injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Intercept adds the given interceptor i, called before serving every request, after the latency and before the faults.
+func (s *Server) Intercept(i Interceptor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interceptors = append(s.interceptors, i)
+}
+
+// ServeHTTP serves the given request req as the Accounts API would.
+func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	latency := s.latency
+	interceptors := append([]Interceptor(nil), s.interceptors...)
+	fault := s.matchFault(req)
+	s.mu.Unlock()
+
+	if latency > 0 {
+		t := time.NewTimer(latency)
+		select {
+		case <-t.C:
+		case <-req.Context().Done():
+			t.Stop()
+			return
+		}
+	}
+
+	for _, i := range interceptors {
+		if i(rw, req) {
+			return
+		}
+	}
+
+	if fault != nil {
+		if fault.RetryAfter > 0 {
+			rw.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Seconds())))
+		}
+		serveError(rw, fault.Status, "injected fault")
+		return
+	}
+
+	switch {
+	case req.URL.Path == accountsPath:
+		switch req.Method {
+		case http.MethodPost:
+			s.create(rw, req)
+		case http.MethodGet:
+			s.list(rw, req)
+		default:
+			serveError(rw, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", req.Method))
+		}
+	case strings.HasPrefix(req.URL.Path, accountsPath+"/"):
+		id := strings.TrimPrefix(req.URL.Path, accountsPath+"/")
+		switch req.Method {
+		case http.MethodGet:
+			s.fetch(rw, id)
+		case http.MethodPatch:
+			s.update(rw, req, id)
+		case http.MethodDelete:
+			s.delete(rw, req, id)
+		default:
+			serveError(rw, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", req.Method))
+		}
+	default:
+		serveError(rw, http.StatusNotFound, fmt.Sprintf("no route for %s", req.URL.Path))
+	}
+}
+
+// matchFault returns the first injected fault matching the given request req, if any, consuming one of its times. It
+// must be called with the lock held.
+func (s *Server) matchFault(req *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != req.Method {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) create(rw http.ResponseWriter, req *http.Request) {
+	var resource client.AccountResource
+	if err := decode(req, &resource); err != nil {
+		serveError(rw, http.StatusBadRequest, fmt.Sprintf("invalid account: %s", err))
+		return
+	}
+
+	a := resource.Data
+	if msg := checkAccount(a); msg != "" {
+		serveError(rw, http.StatusBadRequest, msg)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.accounts[a.ID]; ok {
+		serveError(rw, http.StatusConflict, "Account cannot be created as it violates a duplicate constraint")
+		return
+	}
+
+	now := s.now().UTC()
+	a.Version = 0
+	a.CreatedOn = &now
+	a.ModifiedOn = &now
+	s.store(a)
+
+	serveContent(rw, http.StatusCreated, client.AccountResource{Data: a})
+}
+
+func (s *Server) fetch(rw http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[id]
+	if !ok {
+		serveError(rw, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+
+	serveContent(rw, http.StatusOK, client.AccountResource{Data: a})
+}
+
+func (s *Server) list(rw http.ResponseWriter, req *http.Request) {
+	qry := req.URL.Query()
+
+	size := int64(defaultPageSize)
+	if v := qry.Get("page[size]"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			serveError(rw, http.StatusBadRequest, fmt.Sprintf("invalid page size %q", v))
+			return
+		}
+		if n > 0 {
+			size = n
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matching []client.Account
+	for _, id := range s.order {
+		if a := s.accounts[id]; matches(a, qry) {
+			matching = append(matching, a)
+		}
+	}
+
+	last := int64(0)
+	if len(matching) > 0 {
+		last = (int64(len(matching)) - 1) / size
+	}
+
+	var number int64
+	switch v := qry.Get("page[number]"); v {
+	case "", "first":
+		number = 0
+	case "last":
+		number = last
+	default:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			serveError(rw, http.StatusBadRequest, fmt.Sprintf("invalid page number %q", v))
+			return
+		}
+		number = n
+	}
+
+	data := []client.Account{}
+	if from := number * size; from < int64(len(matching)) {
+		to := from + size
+		if to > int64(len(matching)) {
+			to = int64(len(matching))
+		}
+		data = matching[from:to]
+	}
+
+	links := &client.Links{
+		Self:  pageLink(qry, number, size),
+		First: pageLink(qry, 0, size),
+		Last:  pageLink(qry, last, size),
+	}
+	if number < last {
+		links.Next = pageLink(qry, number+1, size)
+	}
+	if number > 0 && number <= last {
+		links.Prev = pageLink(qry, number-1, size)
+	}
+
+	serveContent(rw, http.StatusOK, client.AccountsResource{Data: data, Links: links})
+}
+
+func (s *Server) update(rw http.ResponseWriter, req *http.Request, id string) {
+	var resource struct {
+		Data struct {
+			Version    *int64                 `json:"version"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := decode(req, &resource); err != nil {
+		serveError(rw, http.StatusBadRequest, fmt.Sprintf("invalid patch: %s", err))
+		return
+	}
+	if resource.Data.Version == nil {
+		serveError(rw, http.StatusBadRequest, "version is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[id]
+	if !ok {
+		serveError(rw, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+	if *resource.Data.Version != a.Version {
+		serveError(rw, http.StatusConflict, "invalid version")
+		return
+	}
+
+	// Patch the serialized attributes, so that only the given ones change
+	var attrs map[string]interface{}
+	b, err := json.Marshal(a.Attributes)
+	if err == nil {
+		err = json.Unmarshal(b, &attrs)
+	}
+	if err != nil {
+		serveError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for k, v := range resource.Data.Attributes {
+		attrs[k] = v
+	}
+
+	var patched client.Attributes
+	b, err = json.Marshal(attrs)
+	if err == nil {
+		err = json.Unmarshal(b, &patched)
+	}
+	if err != nil {
+		serveError(rw, http.StatusBadRequest, fmt.Sprintf("invalid patch: %s", err))
+		return
+	}
+
+	now := s.now().UTC()
+	a.Attributes = patched
+	a.Version++
+	a.ModifiedOn = &now
+	s.store(a)
+
+	serveContent(rw, http.StatusOK, client.AccountResource{Data: a})
+}
+
+func (s *Server) delete(rw http.ResponseWriter, req *http.Request, id string) {
+	v := req.URL.Query().Get("version")
+	version, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		serveError(rw, http.StatusBadRequest, fmt.Sprintf("invalid version %q", v))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[id]
+	if !ok {
+		serveError(rw, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+	if version != a.Version {
+		serveError(rw, http.StatusConflict, "invalid version")
+		return
+	}
+
+	delete(s.accounts, id)
+	for i, oid := range s.order {
+		if oid == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// store stores the given account a, keeping its position if it already exists. It must be called with the lock held.
+func (s *Server) store(a client.Account) {
+	if _, ok := s.accounts[a.ID]; !ok {
+		s.order = append(s.order, a.ID)
+	}
+	s.accounts[a.ID] = a
+}
+
+// checkAccount returns why the given account a would be rejected by the Accounts API, or an empty string if it would
+// not. Only the checks of the service are made; use client.Account.Validate() for the Form3 rules.
+func checkAccount(a client.Account) string {
+	switch {
+	case a.ID == "":
+		return "id is required"
+	case a.OrganisationID == "":
+		return "organisation_id is required"
+	case a.Type != client.TypeAccounts:
+		return `type must be "accounts"`
+	case a.Attributes.Country == "":
+		return "country is required"
+	}
+
+	// The unknown values of the typed fields cannot be serialized
+	if _, err := json.Marshal(a); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// matches reports whether the given account a matches the filters of the given query qry.
+func matches(a client.Account, qry url.Values) bool {
+	filters := map[string]string{
+		"filter[bank_id]":        a.Attributes.BankID,
+		"filter[bank_id_code]":   string(a.Attributes.BankIDCode),
+		"filter[account_number]": a.Attributes.AccountNumber,
+		"filter[iban]":           a.Attributes.IBAN,
+		"filter[country]":        string(a.Attributes.Country),
+		"filter[customer_id]":    a.Attributes.CustomerID,
+	}
+	for k, v := range filters {
+		if want := qry.Get(k); want != "" && want != v {
+			return false
+		}
+	}
+	return true
+}
+
+// pageLink returns the link to the page with the given number and size, keeping the filters of the given query qry.
+func pageLink(qry url.Values, number, size int64) string {
+	q := make(url.Values)
+	for k, v := range qry {
+		if strings.HasPrefix(k, "filter[") {
+			q[k] = v
+		}
+	}
+	q.Set("page[number]", strconv.FormatInt(number, 10))
+	q.Set("page[size]", strconv.FormatInt(size, 10))
+	return accountsPath + "?" + q.Encode()
+}
+
+// decode decodes the JSON body of the given request req into v.
+func decode(req *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func serveError(rw http.ResponseWriter, statusCode int, msg string) {
+	serveContent(rw, statusCode, errorBody{ErrorCode: strconv.Itoa(statusCode), ErrorMessage: msg})
+}
+
+func serveContent(rw http.ResponseWriter, statusCode int, content interface{}) {
+	body, err := json.Marshal(content)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		body = []byte(fmt.Sprintf(`{"error_message": %q}`, err.Error()))
+	}
+
+	rw.Header().Set("Content-Type", "application/vnd.api+json")
+	rw.WriteHeader(statusCode)
+	_, _ = rw.Write(body)
+}
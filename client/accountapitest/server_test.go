@@ -0,0 +1,222 @@
+// +build unit
+
+package accountapitest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/kitolabs-private/form3/interview-accountapi/client"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const organisationID = "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
+
+func accountOf(id, country, accountNumber string) client.Account {
+	return client.Account{
+		ID:             id,
+		OrganisationID: organisationID,
+		Type:           client.TypeAccounts,
+		Attributes: client.Attributes{
+			Country:       client.Country(country),
+			AccountNumber: accountNumber,
+		},
+	}
+}
+
+var (
+	accountOne   = accountOf("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "GB", "41426819")
+	accountTwo   = accountOf("ad27e265-9605-4b4b-a0e5-3003ea9cc4dd", "GB", "41426820")
+	accountThree = accountOf("ad27e265-9605-4b4b-a0e5-3003ea9cc4de", "DE", "41426821")
+)
+
+func setupClient(t *testing.T, s *Server) *client.Client {
+	c, err := client.New(s.URL, client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("failed to setup client. %s", err)
+	}
+	return c
+}
+
+func TestCreateFetchDelete(t *testing.T) {
+	s := NewServer(accountOne)
+	defer s.Close()
+	created := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.now = func() time.Time { return created }
+
+	c := setupClient(t, s)
+
+	got, err := c.Create(&client.AccountResource{Data: accountTwo})
+	assert.NoError(t, err)
+	assert.Equal(t, &created, got.Data.CreatedOn)
+	stored, ok := s.Account(accountTwo.ID)
+	assert.True(t, ok)
+	assert.Equal(t, got.Data, stored)
+
+	_, err = c.Create(&client.AccountResource{Data: accountOne})
+	assert.True(t, errors.Is(err, client.ErrConflict), fmt.Sprintf("Want conflict, but got %+v", err))
+
+	_, err = c.Create(&client.AccountResource{Data: accountOf("", "GB", "")})
+	assert.True(t, errors.Is(err, client.ErrBadInput), fmt.Sprintf("Want bad input, but got %+v", err))
+	_, err = c.Create(&client.AccountResource{Data: accountOf(accountThree.ID, "", "")})
+	assert.True(t, errors.Is(err, client.ErrBadInput), fmt.Sprintf("Want bad input, but got %+v", err))
+
+	fetched, err := c.Fetch(accountTwo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, got, fetched)
+
+	var apiErr *client.APIError
+	_, err = c.Fetch(accountThree.ID)
+	assert.True(t, errors.As(err, &apiErr))
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+	assert.Equal(t, "404", apiErr.ErrorCode)
+	assert.Contains(t, apiErr.ErrorMessage, accountThree.ID)
+
+	err = c.Delete(accountTwo.ID, 1)
+	assert.True(t, errors.Is(err, client.ErrConflict), fmt.Sprintf("Want conflict, but got %+v", err))
+	assert.NoError(t, c.Delete(accountTwo.ID, 0))
+	err = c.Delete(accountTwo.ID, 0)
+	assert.True(t, errors.Is(err, client.ErrNotFound), fmt.Sprintf("Want not found, but got %+v", err))
+
+	assert.Equal(t, []client.Account{accountOne}, s.Accounts())
+}
+
+func TestUpdate(t *testing.T) {
+	s := NewServer(accountOne)
+	defer s.Close()
+
+	c := setupClient(t, s)
+	ctx := context.Background()
+
+	got, err := c.Update(ctx, accountOne.ID, 0, client.AccountPatch{BankAccountName: client.StringOf("New Name")})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), got.Data.Version)
+	assert.Equal(t, "New Name", got.Data.Attributes.BankAccountName)
+	assert.Equal(t, accountOne.Attributes.AccountNumber, got.Data.Attributes.AccountNumber)
+
+	_, err = c.Update(ctx, accountOne.ID, 0, client.AccountPatch{})
+	assert.True(t, errors.Is(err, client.ErrConflict), fmt.Sprintf("Want conflict, but got %+v", err))
+	_, err = c.Update(ctx, accountTwo.ID, 0, client.AccountPatch{})
+	assert.True(t, errors.Is(err, client.ErrNotFound), fmt.Sprintf("Want not found, but got %+v", err))
+}
+
+func TestList(t *testing.T) {
+	type testData struct {
+		opts    client.ListOpts
+		want    []client.Account
+		hasNext bool
+		hasPrev bool
+		err     error
+	}
+
+	var golds = []testData{
+		0: {client.ListOpts{}, []client.Account{accountOne, accountTwo, accountThree}, false, false, nil},
+		1: {
+			client.ListOpts{PageOpts: client.PageOpts{Number: client.PageNumOptOf("0"), Size: client.PageSizeOptOf(2)}},
+			[]client.Account{accountOne, accountTwo}, true, false, nil,
+		},
+		2: {
+			client.ListOpts{PageOpts: client.PageOpts{Number: client.PageNumOptOf("last"), Size: client.PageSizeOptOf(2)}},
+			[]client.Account{accountThree}, false, true, nil,
+		},
+		3: {
+			client.ListOpts{PageOpts: client.PageOpts{Number: client.PageNumOptOf("5"), Size: client.PageSizeOptOf(2)}},
+			[]client.Account{}, false, false, nil,
+		},
+		4: {
+			client.ListOpts{Filter: &client.AccountFilter{Country: "GB", AccountNumber: "41426820"}},
+			[]client.Account{accountTwo}, false, false, nil,
+		},
+		5: {
+			client.ListOpts{PageOpts: client.PageOpts{Number: client.PageNumOptOf("bad-page-number")}},
+			nil, false, false, client.ErrBadInput,
+		},
+		6: {
+			client.ListOpts{PageOpts: client.PageOpts{Size: client.PageSizeOptOf(-1)}},
+			nil, false, false, client.ErrBadInput,
+		},
+	}
+
+	s := NewServer(accountOne, accountTwo, accountThree)
+	defer s.Close()
+
+	c := setupClient(t, s)
+
+	for i, g := range golds {
+		got, err := c.ListWithOpts(context.Background(), &g.opts)
+
+		assert.True(t, errors.Is(err, g.err), fmt.Sprintf("%d. Want error %+v, but got %+v", i, g.err, err))
+		if g.err != nil {
+			continue
+		}
+		assert.Equal(t, g.want, got.Data, fmt.Sprintf("%d. Unexpected accounts", i))
+		assert.Equal(t, g.hasNext, got.HasNext(), fmt.Sprintf("%d. Unexpected next link", i))
+		assert.Equal(t, g.hasPrev, got.HasPrev(), fmt.Sprintf("%d. Unexpected prev link", i))
+	}
+
+	// The links keep the filters
+	var ids []string
+	it := c.ListAllFiltered(context.Background(), 1, &client.AccountFilter{Country: "GB"})
+	for it.Next() {
+		ids = append(ids, it.Account().ID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{accountOne.ID, accountTwo.ID}, ids)
+}
+
+func TestFaults(t *testing.T) {
+	s := NewServer(accountOne)
+	defer s.Close()
+
+	c := setupClient(t, s)
+
+	s.InjectFault(Fault{Method: http.MethodDelete, Status: http.StatusInternalServerError})
+	s.InjectFault(Fault{Status: http.StatusTooManyRequests, RetryAfter: 2 * time.Second, Times: 1})
+
+	// The faults are matched in order, and only as many times as told
+	var apiErr *client.APIError
+	_, err := c.Fetch(accountOne.ID)
+	assert.True(t, errors.As(err, &apiErr))
+	assert.True(t, errors.Is(err, client.ErrRateLimited))
+	assert.Equal(t, 2*time.Second, apiErr.RetryAfter)
+	_, err = c.Fetch(accountOne.ID)
+	assert.NoError(t, err)
+
+	err = c.Delete(accountOne.ID, 0)
+	assert.True(t, errors.Is(err, client.ErrServerError), fmt.Sprintf("Want server error, but got %+v", err))
+	err = c.Delete(accountOne.ID, 0)
+	assert.True(t, errors.Is(err, client.ErrServerError), fmt.Sprintf("Want server error, but got %+v", err))
+
+	s.ClearFaults()
+	assert.NoError(t, c.Delete(accountOne.ID, 0))
+
+	// The interceptors take over the requests
+	s.Intercept(func(rw http.ResponseWriter, req *http.Request) bool {
+		if req.Method != http.MethodGet {
+			return false
+		}
+		rw.WriteHeader(http.StatusBadGateway)
+		return true
+	})
+	_, err = c.Fetch(accountOne.ID)
+	assert.True(t, errors.Is(err, client.ErrServerError), fmt.Sprintf("Want server error, but got %+v", err))
+
+	assert.Equal(t, 6, s.Requests())
+}
+
+func TestLatency(t *testing.T) {
+	s := NewServer(accountOne)
+	defer s.Close()
+	s.SetLatency(time.Second)
+
+	c := setupClient(t, s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.FetchContext(ctx, accountOne.ID)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), fmt.Sprintf("Want deadline exceeded, but got %+v", err))
+}
@@ -0,0 +1,238 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BreakerState represents the state of the circuit breaker of a Client.
+type BreakerState int
+
+const (
+	// BreakerClosed lets every request through, while counting the consecutive failures.
+	BreakerClosed BreakerState = iota
+
+	// BreakerOpen fails every request fast with ErrCircuitOpen, until the cool-down elapses.
+	BreakerOpen
+
+	// BreakerHalfOpen lets a few trial requests through: they close the circuit if they succeed, or open it again if
+	// any of them fails.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerSettings represents the configuration of the circuit breaker of a Client. Use it in the function
+// WithCircuitBreaker(). The zero values are replaced by the defaults.
+type BreakerSettings struct {
+	// FailureThreshold is the number of consecutive failed attempts which opens the circuit. It defaults to 5.
+	FailureThreshold int
+
+	// CoolDown is how long the circuit stays open before letting the trial requests through. It defaults to 30 seconds.
+	CoolDown time.Duration
+
+	// SuccessThreshold is the number of consecutive successful trial requests which closes the circuit, which is also
+	// the number of trial requests let through concurrently. It defaults to 1.
+	SuccessThreshold int
+
+	// IsFailure reports whether the outcome of an attempt, either the response resp or the transport error err, is a
+	// failure. It defaults to the 5xx responses and the transport errors, except the cancellations.
+	IsFailure func(resp *http.Response, err error) bool
+
+	// OnStateChange, if set, is called on every state change of the circuit, e.g. to alert. It is called synchronously
+	// by the request which caused the change, so it should not block.
+	OnStateChange func(from, to BreakerState)
+}
+
+// DefaultBreakerSettings are the BreakerSettings which replace the zero values.
+var DefaultBreakerSettings = BreakerSettings{
+	FailureThreshold: 5,
+	CoolDown:         30 * time.Second,
+	SuccessThreshold: 1,
+	IsFailure:        isBreakerFailure,
+}
+
+// circuitBreaker stops sending requests to the Accounts API once it keeps failing, and probes it again after a
+// cool-down. It is shared by all the concurrent calls of the Client.
+type circuitBreaker struct {
+	settings BreakerSettings
+	onChange func(from, to BreakerState)
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	successes int
+	trials    int
+	openedAt  time.Time
+
+	// generation changes on every state change, so that the outcomes of the requests let through in a previous state
+	// are ignored.
+	generation uint64
+
+	// now returns the current time. It is only overridden in the tests.
+	now func() time.Time
+}
+
+// stateChange represents a state change of the circuit, notified once the lock is released.
+type stateChange struct {
+	from, to BreakerState
+}
+
+// WithCircuitBreaker protects the Accounts API with a circuit breaker configured with the given settings s. Once the
+// circuit opens, the calls fail fast with ErrCircuitOpen, without sending any request. Every attempt counts, including
+// the retries. The state changes are logged at warn level.
+func WithCircuitBreaker(s BreakerSettings) Option {
+	return func(c *Client) error {
+		if s.FailureThreshold < 0 || s.CoolDown < 0 || s.SuccessThreshold < 0 {
+			return fmt.Errorf("%w: negative circuit breaker setting", ErrInvalidOption)
+		}
+		if s.FailureThreshold == 0 {
+			s.FailureThreshold = DefaultBreakerSettings.FailureThreshold
+		}
+		if s.CoolDown == 0 {
+			s.CoolDown = DefaultBreakerSettings.CoolDown
+		}
+		if s.SuccessThreshold == 0 {
+			s.SuccessThreshold = DefaultBreakerSettings.SuccessThreshold
+		}
+		if s.IsFailure == nil {
+			s.IsFailure = DefaultBreakerSettings.IsFailure
+		}
+
+		c.breaker = &circuitBreaker{
+			settings: s,
+			onChange: func(from, to BreakerState) {
+				c.log().Warn("circuit breaker state changed", "from", from, "to", to)
+				if s.OnStateChange != nil {
+					s.OnStateChange(from, to)
+				}
+			},
+			now: time.Now,
+		}
+		return nil
+	}
+}
+
+// CircuitState returns the current state of the circuit breaker of the Client, or BreakerClosed if it has none.
+func (c *Client) CircuitState() BreakerState {
+	if c.breaker == nil {
+		return BreakerClosed
+	}
+
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+	return c.breaker.state
+}
+
+// isBreakerFailure reports whether the outcome of an attempt, either the response resp or the error err, is a 5xx
+// response or a transport error other than a cancellation.
+func isBreakerFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// allow reports whether a request may be sent, returning ErrCircuitOpen otherwise. The returned generation must be
+// given back in done() or release().
+func (b *circuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	var changes []stateChange
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.settings.CoolDown {
+		changes = append(changes, b.setState(BreakerHalfOpen))
+	}
+
+	var err error
+	switch b.state {
+	case BreakerOpen:
+		err = ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.trials >= b.settings.SuccessThreshold {
+			err = ErrCircuitOpen
+		} else {
+			b.trials++
+		}
+	}
+	generation := b.generation
+	b.mu.Unlock()
+
+	b.notify(changes)
+	return generation, err
+}
+
+// done records the outcome of a request let through in the given generation, either the response resp or the error
+// err.
+func (b *circuitBreaker) done(generation uint64, resp *http.Response, err error) {
+	failure := b.settings.IsFailure(resp, err)
+
+	b.mu.Lock()
+	var changes []stateChange
+	if generation == b.generation {
+		switch b.state {
+		case BreakerClosed:
+			if !failure {
+				b.failures = 0
+			} else if b.failures++; b.failures >= b.settings.FailureThreshold {
+				changes = append(changes, b.setState(BreakerOpen))
+			}
+		case BreakerHalfOpen:
+			b.trials--
+			if failure {
+				changes = append(changes, b.setState(BreakerOpen))
+			} else if b.successes++; b.successes >= b.settings.SuccessThreshold {
+				changes = append(changes, b.setState(BreakerClosed))
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(changes)
+}
+
+// release gives back a request let through in the given generation, which was not sent after all.
+func (b *circuitBreaker) release(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation == b.generation && b.state == BreakerHalfOpen {
+		b.trials--
+	}
+}
+
+// setState moves the circuit to the given state to, starting a new generation. It must be called with the lock held.
+func (b *circuitBreaker) setState(to BreakerState) stateChange {
+	change := stateChange{from: b.state, to: to}
+
+	b.state = to
+	b.generation++
+	b.failures = 0
+	b.successes = 0
+	b.trials = 0
+	if to == BreakerOpen {
+		b.openedAt = b.now()
+	}
+
+	return change
+}
+
+// notify calls the state change callback for each of the given changes.
+func (b *circuitBreaker) notify(changes []stateChange) {
+	for _, ch := range changes {
+		b.onChange(ch.from, ch.to)
+	}
+}
@@ -0,0 +1,129 @@
+// +build unit
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var failing int32 = 1
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			serveError(t, rw, http.StatusInternalServerError)
+			return
+		}
+		serveContent(t, rw, http.StatusOK, AccountResource{Data: accountOne})
+	}))
+	defer server.Close()
+
+	var changes []string
+	c, err := New(server.URL,
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithCircuitBreaker(BreakerSettings{
+			FailureThreshold: 2,
+			CoolDown:         time.Minute,
+			SuccessThreshold: 2,
+			OnStateChange: func(from, to BreakerState) {
+				changes = append(changes, fmt.Sprintf("%s->%s", from, to))
+			},
+		}))
+	assert.NoError(t, err)
+
+	now := time.Now()
+	c.breaker.now = func() time.Time { return now }
+
+	fetch := func() error {
+		_, err := c.Fetch(accountOne.ID)
+		return err
+	}
+
+	// The consecutive failures open the circuit
+	assert.True(t, errors.Is(fetch(), ErrServerError))
+	assert.Equal(t, BreakerClosed, c.CircuitState())
+	assert.True(t, errors.Is(fetch(), ErrServerError))
+	assert.Equal(t, BreakerOpen, c.CircuitState())
+
+	// The open circuit fails fast
+	assert.True(t, errors.Is(fetch(), ErrCircuitOpen))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// A failed trial opens the circuit again
+	now = now.Add(time.Minute)
+	assert.True(t, errors.Is(fetch(), ErrServerError))
+	assert.Equal(t, BreakerOpen, c.CircuitState())
+	assert.True(t, errors.Is(fetch(), ErrCircuitOpen))
+
+	// The successful trials close the circuit
+	atomic.StoreInt32(&failing, 0)
+	now = now.Add(time.Minute)
+	assert.NoError(t, fetch())
+	assert.Equal(t, BreakerHalfOpen, c.CircuitState())
+	assert.NoError(t, fetch())
+	assert.Equal(t, BreakerClosed, c.CircuitState())
+
+	assert.Equal(t, []string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}, changes)
+}
+
+func TestCircuitBreakerHalfOpenTrials(t *testing.T) {
+	c, err := New("http://localhost:8080", WithCircuitBreaker(BreakerSettings{FailureThreshold: 1}))
+	assert.NoError(t, err)
+
+	b := c.breaker
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	gen, err := b.allow()
+	assert.NoError(t, err)
+	b.done(gen, nil, errors.New("connection refused"))
+	assert.Equal(t, BreakerOpen, c.CircuitState())
+
+	// Only one trial is let through at a time
+	now = now.Add(DefaultBreakerSettings.CoolDown)
+	gen, err = b.allow()
+	assert.NoError(t, err)
+	_, err = b.allow()
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	// A trial which is not sent gives its place back
+	b.release(gen)
+	gen, err = b.allow()
+	assert.NoError(t, err)
+
+	// The outcomes of a previous state are ignored
+	b.done(gen-1, nil, errors.New("connection refused"))
+	assert.Equal(t, BreakerHalfOpen, c.CircuitState())
+
+	b.done(gen, &http.Response{StatusCode: http.StatusNotFound}, nil)
+	assert.Equal(t, BreakerClosed, c.CircuitState())
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	var golds = []BreakerSettings{
+		0: {FailureThreshold: -1},
+		1: {CoolDown: -time.Second},
+		2: {SuccessThreshold: -1},
+	}
+
+	for i, g := range golds {
+		_, err := New("http://localhost:8080", WithCircuitBreaker(g))
+
+		assert.True(t, errors.Is(err, ErrInvalidOption), fmt.Sprintf("%d. Want error %+v, but got %+v", i, ErrInvalidOption, err))
+	}
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"gitlab.com/kitolabs-private/form3/interview-accountapi/client/iban"
+	"io"
+)
+
+// defaultCurrencies are the base currencies set by AccountBuilder, keyed by country. It defaults to EUR otherwise.
+var defaultCurrencies = map[Country]Currency{
+	CountryAU: CurrencyAUD,
+	CountryCA: CurrencyCAD,
+	CountryCH: CurrencyCHF,
+	CountryGB: CurrencyGBP,
+	CountryHK: CurrencyHKD,
+	CountryPL: CurrencyPLN,
+	CountryUS: CurrencyUSD,
+}
+
+// AccountBuilder builds an AccountResource step by step, filling in the defaults of its country. Use it as follows:
+//
+//	a, err := client.NewUKAccount(orgID).
+//		WithSortCode("400300").
+//		WithAccountNumber("41426819").
+//		WithBIC("NWBKGB22").
+//		Build()
+type AccountBuilder struct {
+	account Account
+}
+
+// NewAccountBuilder returns an AccountBuilder of an account held in the given country by the organisation referenced by
+// the given orgID. The account gets a fresh random ID, and the bank ID code and base currency of the country.
+func NewAccountBuilder(orgID string, country Country) *AccountBuilder {
+	currency, ok := defaultCurrencies[country]
+	if !ok {
+		currency = CurrencyEUR
+	}
+
+	return &AccountBuilder{
+		account: Account{
+			ID:             newUUID(),
+			OrganisationID: orgID,
+			Type:           TypeAccounts,
+			Attributes: Attributes{
+				Country:               country,
+				BaseCurrency:          currency,
+				BankIDCode:            countryRules[country].bankIDCode,
+				AccountClassification: ClassificationPersonal,
+			},
+		},
+	}
+}
+
+// NewUKAccount returns an AccountBuilder of an account held in the United Kingdom. Set its sort code with
+// WithSortCode().
+func NewUKAccount(orgID string) *AccountBuilder {
+	return NewAccountBuilder(orgID, CountryGB)
+}
+
+// NewDEAccount returns an AccountBuilder of an account held in Germany. Set its Bankleitzahl with WithBankID().
+func NewDEAccount(orgID string) *AccountBuilder {
+	return NewAccountBuilder(orgID, CountryDE)
+}
+
+// NewFRAccount returns an AccountBuilder of an account held in France. Set its bank and branch codes with WithBankID().
+func NewFRAccount(orgID string) *AccountBuilder {
+	return NewAccountBuilder(orgID, CountryFR)
+}
+
+// NewNLAccount returns an AccountBuilder of an account held in the Netherlands, which requires a BIC but no bank ID.
+func NewNLAccount(orgID string) *AccountBuilder {
+	return NewAccountBuilder(orgID, CountryNL)
+}
+
+// NewUSAccount returns an AccountBuilder of an account held in the United States. Set its ABA routing number with
+// WithBankID().
+func NewUSAccount(orgID string) *AccountBuilder {
+	return NewAccountBuilder(orgID, CountryUS)
+}
+
+// WithID overrides the random ID of the account.
+func (b *AccountBuilder) WithID(id string) *AccountBuilder {
+	b.account.ID = id
+	return b
+}
+
+// WithBankID sets the bank ID of the account.
+func (b *AccountBuilder) WithBankID(bankID string) *AccountBuilder {
+	b.account.Attributes.BankID = bankID
+	return b
+}
+
+// WithSortCode sets the sort code of a UK account, i.e. its bank ID.
+func (b *AccountBuilder) WithSortCode(sortCode string) *AccountBuilder {
+	return b.WithBankID(sortCode)
+}
+
+// WithAccountNumber sets the account number.
+func (b *AccountBuilder) WithAccountNumber(accountNumber string) *AccountBuilder {
+	b.account.Attributes.AccountNumber = accountNumber
+	return b
+}
+
+// WithBIC sets the BIC of the bank of the account.
+func (b *AccountBuilder) WithBIC(bic string) *AccountBuilder {
+	b.account.Attributes.BIC = bic
+	return b
+}
+
+// WithIBAN sets the IBAN of the account. Otherwise, Build() generates it when possible.
+func (b *AccountBuilder) WithIBAN(s string) *AccountBuilder {
+	b.account.Attributes.IBAN = s
+	return b
+}
+
+// WithBaseCurrency overrides the base currency of the country.
+func (b *AccountBuilder) WithBaseCurrency(currency Currency) *AccountBuilder {
+	b.account.Attributes.BaseCurrency = currency
+	return b
+}
+
+// WithClassification overrides the default classification, ClassificationPersonal.
+func (b *AccountBuilder) WithClassification(classification AccountClassification) *AccountBuilder {
+	b.account.Attributes.AccountClassification = classification
+	return b
+}
+
+// WithName sets up to four lines of the account holder name.
+func (b *AccountBuilder) WithName(lines ...string) *AccountBuilder {
+	b.account.Attributes.Name = lines
+	return b
+}
+
+// WithBankAccountName sets the name of the account, along with its alternative names, if any.
+func (b *AccountBuilder) WithBankAccountName(name string, alternatives ...string) *AccountBuilder {
+	b.account.Attributes.BankAccountName = name
+	b.account.Attributes.AlternativeBankAccountNames = alternatives
+	return b
+}
+
+// WithCustomerID sets the customer ID of the account holder.
+func (b *AccountBuilder) WithCustomerID(customerID string) *AccountBuilder {
+	b.account.Attributes.CustomerID = customerID
+	return b
+}
+
+// WithSecondaryIdentification sets the secondary identification of the account (e.g. a building society roll number).
+func (b *AccountBuilder) WithSecondaryIdentification(id string) *AccountBuilder {
+	b.account.Attributes.SecondaryIdentification = id
+	return b
+}
+
+// WithJointAccount sets whether the account is held by more than one person.
+func (b *AccountBuilder) WithJointAccount(joint bool) *AccountBuilder {
+	b.account.Attributes.JointAccount = joint
+	return b
+}
+
+// WithAccountMatchingOptOut sets whether the account opts out of account matching (e.g. Confirmation of Payee).
+func (b *AccountBuilder) WithAccountMatchingOptOut(optOut bool) *AccountBuilder {
+	b.account.Attributes.AccountMatchingOptOut = optOut
+	return b
+}
+
+// Build returns the built AccountResource, once validated with Account.Validate(). The IBAN, if not set, is generated
+// from the country, bank ID, account number and BIC, when the country supports it.
+func (b *AccountBuilder) Build() (*AccountResource, error) {
+	a := b.account
+	a.Attributes.AlternativeBankAccountNames = append([]string(nil), a.Attributes.AlternativeBankAccountNames...)
+	a.Attributes.Name = append([]string(nil), a.Attributes.Name...)
+
+	if a.Attributes.IBAN == "" && countryRules[a.Attributes.Country].ibanAllowed {
+		a.Attributes.IBAN = ibanOf(a.Attributes)
+	}
+
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &AccountResource{Data: a}, nil
+}
+
+// ibanOf returns the IBAN of the given attributes attrs, or an empty string if it cannot be generated.
+func ibanOf(attrs Attributes) string {
+	if attrs.AccountNumber == "" {
+		return ""
+	}
+
+	country := string(attrs.Country)
+	s, err := iban.Build(country, attrs.BankID, attrs.AccountNumber)
+	if errors.Is(err, iban.ErrNotBuildable) && len(attrs.BIC) >= 4 {
+		s, err = iban.BuildWithBankCode(country, attrs.BIC[:4], attrs.BankID, attrs.AccountNumber)
+	}
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	if _, err := io.ReadFull(rand.Reader, b[:]); err != nil {
+		panic(fmt.Sprintf("failed to generate UUID. %s", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,72 @@
+// +build unit
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAccountBuilder(t *testing.T) {
+	orgID := accountOne.OrganisationID
+
+	got, err := NewUKAccount(orgID).
+		WithSortCode("400300").
+		WithAccountNumber("41426819").
+		WithBIC("NWBKGB22").
+		WithBankAccountName("Samantha Holder", "Sam Holder").
+		Build()
+
+	assert.NoError(t, err)
+	assert.Regexp(t, uuidPattern, got.Data.ID)
+	assert.Equal(t, orgID, got.Data.OrganisationID)
+	assert.Equal(t, TypeAccounts, got.Data.Type)
+	assert.Equal(t, Attributes{
+		Country:                     CountryGB,
+		BaseCurrency:                CurrencyGBP,
+		BankID:                      "400300",
+		BankIDCode:                  BankIDCodeGBDSC,
+		AccountNumber:               "41426819",
+		BIC:                         "NWBKGB22",
+		IBAN:                        "GB16NWBK40030041426819",
+		BankAccountName:             "Samantha Holder",
+		AlternativeBankAccountNames: []string{"Sam Holder"},
+		AccountClassification:       ClassificationPersonal,
+	}, got.Data.Attributes)
+
+	// Every build gets a fresh ID
+	other, err := NewUKAccount(orgID).WithSortCode("400300").WithBIC("NWBKGB22").Build()
+	assert.NoError(t, err)
+	assert.NotEqual(t, got.Data.ID, other.Data.ID)
+}
+
+func TestAccountBuilderPresets(t *testing.T) {
+	type testData struct {
+		builder *AccountBuilder
+		iban    string
+		err     error
+	}
+
+	orgID := accountOne.OrganisationID
+
+	var golds = []testData{
+		0: {NewDEAccount(orgID).WithBankID("37040044").WithAccountNumber("532013000"), "DE89370400440532013000", nil},
+		1: {NewFRAccount(orgID).WithBankID("2004101005").WithAccountNumber("0500013M02"), "", nil},
+		2: {NewNLAccount(orgID).WithBIC("ABNANL2A").WithAccountNumber("0417164300"), "NL91ABNA0417164300", nil},
+		3: {NewUSAccount(orgID).WithBankID("021000021").WithBIC("CHASUS33").WithAccountNumber("123456789"), "", nil},
+		4: {NewUKAccount(orgID).WithSortCode("40030"), "", ErrInvalidAccount},
+		5: {NewUSAccount(orgID).WithBankID("021000021"), "", ErrInvalidAccount},
+		6: {NewUKAccount("not-a-uuid").WithSortCode("400300").WithBIC("NWBKGB22"), "", ErrInvalidAccount},
+	}
+
+	for i, g := range golds {
+		got, err := g.builder.Build()
+
+		assert.True(t, errors.Is(err, g.err), fmt.Sprintf("%d. Want error %+v, but got %+v", i, g.err, err))
+		if err == nil {
+			assert.Equal(t, g.iban, got.Data.Attributes.IBAN, fmt.Sprintf("%d. Unexpected IBAN", i))
+		}
+	}
+}
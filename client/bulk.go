@@ -0,0 +1,325 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// defaultBulkConcurrency is the number of concurrent calls of the bulk operations when none is given.
+const defaultBulkConcurrency = 8
+
+// ErrorPolicy represents how a bulk operation reacts to a failed item.
+type ErrorPolicy int
+
+const (
+	// ContinueOnError keeps processing the remaining items after a failure.
+	ContinueOnError ErrorPolicy = iota
+
+	// StopOnError stops processing new items after the first failure. The items being processed at the time are let
+	// finish, and the remaining ones are reported as skipped with ErrSkipped.
+	StopOnError
+)
+
+// BulkOpts represents the options of the bulk operations: BulkCreate(), BulkFetch() and BulkDelete().
+type BulkOpts struct {
+	// Concurrency is the maximum number of concurrent calls. It defaults to 8 when zero.
+	Concurrency int
+
+	// RateLimit limits the calls of the bulk operation to the given rate (requests per second), allowing bursts of up to
+	// Burst calls. It applies on top of the rate limit of the Client, if any. Zero means no limit.
+	RateLimit float64
+	Burst     int
+
+	// ErrorPolicy is the reaction to a failed item. It defaults to ContinueOnError.
+	ErrorPolicy ErrorPolicy
+}
+
+// AccountRef references a given version of an account. Use it in BulkDelete().
+type AccountRef struct {
+	ID      string
+	Version int64
+}
+
+// BulkResult represents the outcome of a single item of a bulk operation.
+type BulkResult struct {
+	// Index is the position of the item in the input.
+	Index int
+
+	// Account is the created or fetched account, if any. It is always nil for BulkDelete().
+	Account *AccountResource
+
+	// Err is the error of the item, if it failed, or ErrSkipped, if it was not processed.
+	Err error
+}
+
+// BulkSummary represents the outcome of a bulk operation: the result of every item, in input order, and their counts.
+// The bulk operations return it along with the context error when their context ends before all the items ran.
+type BulkSummary struct {
+	Results   []BulkResult
+	Succeeded int
+	Failed    int
+	Skipped   int
+}
+
+// Err returns the error of the first failed item in input order, if any. The skipped items are not failures.
+func (s *BulkSummary) Err() error {
+	for _, r := range s.Results {
+		if r.Err != nil && r.Err != ErrSkipped {
+			return r.Err
+		}
+	}
+	return nil
+}
+
+// bulkTask is a single item of a bulk operation, ready to be run. It returns the created or fetched account, if any.
+type bulkTask func(ctx context.Context) (*AccountResource, error)
+
+// BulkCreate creates the given accounts concurrently, according to the given options opts (nil for the defaults).
+func (c *Client) BulkCreate(ctx context.Context, accounts []*AccountResource, opts *BulkOpts) (*BulkSummary, error) {
+	tasks := make(chan bulkTask, len(accounts))
+	for _, a := range accounts {
+		tasks <- c.createTask(a)
+	}
+	close(tasks)
+	return c.runBulk(ctx, opts, tasks)
+}
+
+// BulkCreateFrom is like BulkCreate, but it takes the accounts from the given channel in, until it is closed or the
+// given context ctx is done.
+func (c *Client) BulkCreateFrom(ctx context.Context, in <-chan *AccountResource, opts *BulkOpts) (*BulkSummary, error) {
+	o, err := bulkOptsOf(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make(chan bulkTask)
+	go func() {
+		defer close(tasks)
+		for {
+			select {
+			case a, ok := <-in:
+				if !ok || !sendTask(ctx, tasks, c.createTask(a)) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c.runBulk(ctx, &o, tasks)
+}
+
+// BulkFetch fetches the accounts referenced by the given accountIDs concurrently, according to the given options opts
+// (nil for the defaults).
+func (c *Client) BulkFetch(ctx context.Context, accountIDs []string, opts *BulkOpts) (*BulkSummary, error) {
+	tasks := make(chan bulkTask, len(accountIDs))
+	for _, id := range accountIDs {
+		tasks <- c.fetchTask(id)
+	}
+	close(tasks)
+	return c.runBulk(ctx, opts, tasks)
+}
+
+// BulkFetchFrom is like BulkFetch, but it takes the account IDs from the given channel in, until it is closed or the
+// given context ctx is done.
+func (c *Client) BulkFetchFrom(ctx context.Context, in <-chan string, opts *BulkOpts) (*BulkSummary, error) {
+	o, err := bulkOptsOf(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make(chan bulkTask)
+	go func() {
+		defer close(tasks)
+		for {
+			select {
+			case id, ok := <-in:
+				if !ok || !sendTask(ctx, tasks, c.fetchTask(id)) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c.runBulk(ctx, &o, tasks)
+}
+
+// BulkDelete deletes the referenced accounts concurrently, according to the given options opts (nil for the defaults).
+func (c *Client) BulkDelete(ctx context.Context, refs []AccountRef, opts *BulkOpts) (*BulkSummary, error) {
+	tasks := make(chan bulkTask, len(refs))
+	for _, ref := range refs {
+		tasks <- c.deleteTask(ref)
+	}
+	close(tasks)
+	return c.runBulk(ctx, opts, tasks)
+}
+
+// BulkDeleteFrom is like BulkDelete, but it takes the account references from the given channel in, until it is closed
+// or the given context ctx is done.
+func (c *Client) BulkDeleteFrom(ctx context.Context, in <-chan AccountRef, opts *BulkOpts) (*BulkSummary, error) {
+	o, err := bulkOptsOf(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make(chan bulkTask)
+	go func() {
+		defer close(tasks)
+		for {
+			select {
+			case ref, ok := <-in:
+				if !ok || !sendTask(ctx, tasks, c.deleteTask(ref)) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c.runBulk(ctx, &o, tasks)
+}
+
+func (c *Client) createTask(account *AccountResource) bulkTask {
+	return func(ctx context.Context) (*AccountResource, error) {
+		return c.CreateContext(ctx, account)
+	}
+}
+
+func (c *Client) fetchTask(accountID string) bulkTask {
+	return func(ctx context.Context) (*AccountResource, error) {
+		return c.FetchContext(ctx, accountID)
+	}
+}
+
+func (c *Client) deleteTask(ref AccountRef) bulkTask {
+	return func(ctx context.Context) (*AccountResource, error) {
+		return nil, c.DeleteContext(ctx, ref.ID, ref.Version)
+	}
+}
+
+// sendTask sends the given task to the channel tasks. It reports false if the given context ctx is done earlier.
+func sendTask(ctx context.Context, tasks chan<- bulkTask, task bulkTask) bool {
+	select {
+	case tasks <- task:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// bulkOptsOf returns the given options opts with the defaults filled in, or ErrInvalidOption if they are not valid.
+func bulkOptsOf(opts *BulkOpts) (BulkOpts, error) {
+	var o BulkOpts
+	if opts != nil {
+		o = *opts
+	}
+	if o.Concurrency < 0 {
+		return o, fmt.Errorf("%w: negative concurrency", ErrInvalidOption)
+	}
+	if o.Concurrency == 0 {
+		o.Concurrency = defaultBulkConcurrency
+	}
+	if o.RateLimit < 0 {
+		return o, fmt.Errorf("%w: negative rate", ErrInvalidOption)
+	}
+	if o.Burst < 1 {
+		o.Burst = 1
+	}
+	return o, nil
+}
+
+// runBulk runs the given tasks with a pool of workers, according to the given options opts. It returns once all the
+// tasks have been either run or skipped, with the error of the given context ctx if it ended in the meantime.
+func (c *Client) runBulk(ctx context.Context, opts *BulkOpts, tasks <-chan bulkTask) (*BulkSummary, error) {
+	o, err := bulkOptsOf(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var limiter *rateLimiter
+	if o.RateLimit > 0 {
+		limiter = newRateLimiter(o.RateLimit, o.Burst)
+	}
+
+	type job struct {
+		index int
+		task  bulkTask
+	}
+
+	var (
+		mu      sync.Mutex
+		results []BulkResult
+		stopped bool
+		wg      sync.WaitGroup
+	)
+
+	record := func(i int, account *AccountResource, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[i].Account = account
+		results[i].Err = err
+		if err != nil && o.ErrorPolicy == StopOnError {
+			stopped = true
+		}
+	}
+
+	// skipping reports whether the tasks not started yet must be skipped
+	skipping := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return stopped || ctx.Err() != nil
+	}
+
+	jobs := make(chan job)
+	for w := 0; w < o.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if limiter != nil {
+					if err := limiter.wait(ctx); err != nil {
+						record(j.index, nil, err)
+						continue
+					}
+				}
+				if skipping() {
+					record(j.index, nil, ErrSkipped)
+					continue
+				}
+				account, err := j.task(ctx)
+				record(j.index, account, err)
+			}
+		}()
+	}
+
+	// Dispatch the tasks in input order, skipping them once stopped. The input is drained, so that it never blocks.
+	for task := range tasks {
+		mu.Lock()
+		i := len(results)
+		results = append(results, BulkResult{Index: i})
+		mu.Unlock()
+
+		if skipping() {
+			record(i, nil, ErrSkipped)
+		} else {
+			jobs <- job{index: i, task: task}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	summary := &BulkSummary{Results: results}
+	for _, r := range results {
+		switch {
+		case r.Err == nil:
+			summary.Succeeded++
+		case r.Err == ErrSkipped:
+			summary.Skipped++
+		default:
+			summary.Failed++
+		}
+	}
+	return summary, ctx.Err()
+}
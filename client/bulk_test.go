@@ -0,0 +1,181 @@
+// +build unit
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBulkCreate(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		var a AccountResource
+		err := json.NewDecoder(req.Body).Decode(&a)
+		assert.NoError(t, err)
+
+		if a.Data.ID == badInput {
+			serveError(t, rw, http.StatusBadRequest)
+		} else {
+			serveContent(t, rw, http.StatusCreated, a)
+		}
+	}))
+	defer server.Close()
+
+	client := setupClient(t, server.URL)
+
+	var accounts []*AccountResource
+	for i := 0; i < 20; i++ {
+		a := accountOne
+		a.ID = fmt.Sprintf("account-%02d", i)
+		if i == 7 {
+			a.ID = badInput
+		}
+		accounts = append(accounts, &AccountResource{Data: a})
+	}
+
+	summary, err := client.BulkCreate(context.Background(), accounts, &BulkOpts{Concurrency: 4})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 19, summary.Succeeded)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, 0, summary.Skipped)
+	assert.True(t, errors.Is(summary.Err(), ErrBadInput))
+	assert.True(t, maxInFlight <= 4, fmt.Sprintf("Want at most 4 concurrent calls, but got %d", maxInFlight))
+	for i, r := range summary.Results {
+		assert.Equal(t, i, r.Index)
+		if i == 7 {
+			assert.True(t, errors.Is(r.Err, ErrBadInput))
+			assert.Nil(t, r.Account)
+		} else {
+			assert.NoError(t, r.Err)
+			assert.Equal(t, accounts[i], r.Account)
+		}
+	}
+}
+
+func TestBulkStopOnError(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		pathSegments := strings.Split(req.URL.Path, "/")
+		if pathSegments[len(pathSegments)-1] == serviceFailure {
+			serveError(t, rw, http.StatusInternalServerError)
+		} else {
+			serveContent(t, rw, http.StatusOK, AccountResource{Data: accountOne})
+		}
+	}))
+	defer server.Close()
+
+	client := setupClient(t, server.URL)
+
+	ids := []string{accountOne.ID, serviceFailure, accountOne.ID, accountOne.ID}
+
+	summary, err := client.BulkFetch(context.Background(), ids, &BulkOpts{Concurrency: 1, ErrorPolicy: StopOnError})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls)
+	assert.Equal(t, 1, summary.Succeeded)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, 2, summary.Skipped)
+	assert.Len(t, summary.Results, len(ids))
+	assert.True(t, errors.Is(summary.Results[1].Err, ErrServerError))
+	assert.Equal(t, ErrSkipped, summary.Results[2].Err)
+	assert.Equal(t, ErrSkipped, summary.Results[3].Err)
+}
+
+func TestBulkCanceled(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		serveContent(t, rw, http.StatusOK, AccountResource{Data: accountOne})
+	}))
+	defer server.Close()
+
+	client := setupClient(t, server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ids := []string{accountOne.ID, accountTwo.ID, accountOne.ID}
+
+	summary, err := client.BulkFetch(ctx, ids, nil)
+
+	// The aborted operation is not reported as a success
+	assert.True(t, errors.Is(err, context.Canceled), fmt.Sprintf("Want error %+v, but got %+v", context.Canceled, err))
+	assert.Equal(t, int32(0), calls)
+	assert.Equal(t, 3, summary.Skipped)
+	assert.Len(t, summary.Results, len(ids))
+}
+
+func TestBulkDeleteFrom(t *testing.T) {
+	var mu sync.Mutex
+	deleted := make(map[string]string)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodDelete, req.Method)
+
+		pathSegments := strings.Split(req.URL.Path, "/")
+		mu.Lock()
+		deleted[pathSegments[len(pathSegments)-1]] = req.URL.Query().Get("version")
+		mu.Unlock()
+
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := setupClient(t, server.URL)
+
+	in := make(chan AccountRef)
+	go func() {
+		defer close(in)
+		for i := 0; i < 10; i++ {
+			in <- AccountRef{ID: fmt.Sprintf("account-%d", i), Version: int64(i)}
+		}
+	}()
+
+	summary, err := client.BulkDeleteFrom(context.Background(), in, &BulkOpts{RateLimit: 1000, Burst: 5})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10, summary.Succeeded)
+	assert.NoError(t, summary.Err())
+	assert.Len(t, deleted, 10)
+	assert.Equal(t, "3", deleted["account-3"])
+}
+
+func TestBulkOpts(t *testing.T) {
+	client := setupClient(t, "http://localhost:8080")
+
+	_, err := client.BulkFetch(context.Background(), []string{"id"}, &BulkOpts{Concurrency: -1})
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+
+	_, err = client.BulkFetchFrom(context.Background(), make(chan string), &BulkOpts{RateLimit: -1})
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+
+	summary, err := client.BulkFetch(context.Background(), nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, summary.Results)
+}
@@ -0,0 +1,336 @@
+// Package cassette provides a record/replay HTTP transport, to run deterministic tests of the client without the
+// Accounts API.
+//
+// In record mode, the Recorder sends the requests to the real service and captures the request/response pairs, which
+// Save() writes to a JSON cassette file with the sensitive headers scrubbed. In replay mode, the Recorder serves the
+// recorded responses offline, and fails the requests which do not match any recorded one:
+//
+//	r, err := cassette.New("testdata/create.json", cassette.ModeReplay)
+//	...
+//	c, err := client.New("http://localhost:8080", client.WithHTTPClient(r.HTTPClient()))
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+)
+
+// Mode represents whether a Recorder records or replays the interactions.
+type Mode int
+
+const (
+	// ModeReplay serves the responses recorded in the cassette, without sending any request.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends the requests to the real service, and records them along with their responses.
+	ModeRecord
+)
+
+// Redacted replaces the values of the scrubbed headers.
+const Redacted = "REDACTED"
+
+// DefaultScrubbedHeaders are the headers scrubbed from the cassettes, unless told otherwise with WithScrubbedHeaders().
+var DefaultScrubbedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"Signature",
+}
+
+var (
+	// ErrNoMatch is returned in replay mode for the requests which do not match any recorded interaction.
+	ErrNoMatch = errors.New("no matching interaction")
+)
+
+// Cassette represents the interactions recorded in a cassette file, in the order they happened.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction represents a recorded request/response pair.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request represents a recorded request. The URL holds only its path and query, so that the cassette can be replayed
+// against any base URL.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response represents a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Matcher reports whether the given request req matches the recorded request rec.
+type Matcher func(req *http.Request, body []byte, rec Request) bool
+
+// Option represents a functional option to configure a Recorder. Use it in the function New().
+type Option func(r *Recorder)
+
+// Recorder is an http.RoundTripper which records or replays the interactions of a cassette. It is safe for concurrent
+// use.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	scrubbed  []string
+	match     Matcher
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New returns a Recorder of the cassette file at the given path, in the given mode, configured with the given options
+// opts. In replay mode, the cassette file must exist.
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: http.DefaultTransport,
+		scrubbed:  DefaultScrubbedHeaders,
+		match:     DefaultMatcher,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if mode == ModeReplay {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette. %w", err)
+		}
+		if err := json.Unmarshal(b, &r.cassette); err != nil {
+			return nil, fmt.Errorf("failed to decode cassette %s. %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// WithTransport makes the Recorder send the requests with the given transport rt in record mode, instead of
+// http.DefaultTransport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = rt
+	}
+}
+
+// WithScrubbedHeaders makes the Recorder scrub the given request and response headers, instead of
+// DefaultScrubbedHeaders.
+func WithScrubbedHeaders(headers ...string) Option {
+	return func(r *Recorder) {
+		r.scrubbed = headers
+	}
+}
+
+// WithMatcher makes the Recorder match the requests with the given matcher m, instead of DefaultMatcher.
+func WithMatcher(m Matcher) Option {
+	return func(r *Recorder) {
+		r.match = m
+	}
+}
+
+// DefaultMatcher matches the requests by method, path and query, and body. The JSON bodies only need to be equivalent.
+func DefaultMatcher(req *http.Request, body []byte, rec Request) bool {
+	if req.Method != rec.Method || req.URL.RequestURI() != rec.URL {
+		return false
+	}
+	return bytes.Equal(body, []byte(rec.Body)) || jsonEqual(body, []byte(rec.Body))
+}
+
+// HTTPClient returns an HTTP client which sends its requests through the Recorder. Use it in client.WithHTTPClient().
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, out, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeRecord {
+		resp, err := r.record(out, body)
+		if err != nil {
+			return nil, err
+		}
+		resp.Request = req
+		return resp, nil
+	}
+
+	if out == req && req.Body != nil {
+		_ = req.Body.Close()
+	}
+	return r.replay(req, body)
+}
+
+// Save writes the recorded interactions to the cassette file, creating its directory if needed. It does nothing in
+// replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(b, '\n'), 0644)
+}
+
+// Unused returns the recorded interactions which were not replayed yet, e.g. to check that a scenario ran to the end.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for i, used := range r.used {
+		if !used {
+			unused = append(unused, r.cassette.Interactions[i])
+		}
+	}
+	return unused
+}
+
+// record sends the given request req with the given body to the real service, and records it along with its response.
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	closeErr := resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if closeErr != nil {
+		return nil, closeErr
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     req.URL.RequestURI(),
+			Headers: r.scrub(req.Header),
+			Body:    string(body),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    r.scrub(resp.Header),
+			Body:       string(respBody),
+		},
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay returns the response of the first unused interaction matching the given request req with the given body.
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !r.match(req, body, interaction.Request) {
+			continue
+		}
+		r.used[i] = true
+
+		rec := interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+			StatusCode:    rec.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        rec.Headers.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(rec.Body))),
+			ContentLength: int64(len(rec.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoMatch, req.Method, req.URL.RequestURI())
+}
+
+// scrub returns a copy of the given headers h, with the values of the scrubbed headers redacted.
+func (r *Recorder) scrub(h http.Header) http.Header {
+	scrubbed := h.Clone()
+	for _, k := range r.scrubbed {
+		if _, ok := scrubbed[http.CanonicalHeaderKey(k)]; ok {
+			scrubbed.Set(k, Redacted)
+		}
+	}
+	return scrubbed
+}
+
+// readBody returns the body of the given request req, along with the request to send in its place: req itself when
+// its body can be read again with GetBody, or else a clone of it carrying the buffered body. req is left unmodified,
+// as required from an http.RoundTripper.
+func readBody(req *http.Request) ([]byte, *http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, req, nil
+	}
+
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		body, err := ioutil.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		return body, req, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	out := req.Clone(req.Context())
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, out, nil
+}
+
+// jsonEqual reports whether the given bodies a and b are equivalent JSON documents.
+func jsonEqual(a, b []byte) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,176 @@
+// +build unit
+
+package cassette
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/kitolabs-private/form3/interview-accountapi/client"
+	"gitlab.com/kitolabs-private/form3/interview-accountapi/client/accountapitest"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var account = client.Account{
+	ID:             "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+	OrganisationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+	Type:           client.TypeAccounts,
+	Attributes: client.Attributes{
+		Country:       client.CountryGB,
+		BankID:        "400300",
+		BankIDCode:    client.BankIDCodeGBDSC,
+		AccountNumber: "41426819",
+	},
+}
+
+// scenario runs the calls of a scenario with the given client c, and returns their outcomes.
+func scenario(c *client.Client) []string {
+	var outcomes []string
+	outcome := func(v interface{}, err error) {
+		outcomes = append(outcomes, fmt.Sprintf("%+v %v", v, err))
+	}
+
+	outcome(c.Create(&client.AccountResource{Data: account}))
+	outcome(c.Fetch(account.ID))
+	outcome(c.Fetch("ad27e265-9605-4b4b-a0e5-3003ea9cc4dd"))
+	outcome(nil, c.Delete(account.ID, 0))
+
+	return outcomes
+}
+
+func newClient(t *testing.T, baseURL string, r *Recorder) *client.Client {
+	c, err := client.New(baseURL,
+		client.WithHTTPClient(r.HTTPClient()),
+		client.WithHeader("Authorization", "Bearer secret-token"),
+		client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("failed to setup client. %s", err)
+	}
+	return c
+}
+
+func TestRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "testdata", "scenario.json")
+
+	// Record against the fake Accounts API
+	server := accountapitest.NewServer()
+	recorder, err := New(path, ModeRecord)
+	assert.NoError(t, err)
+	recorded := scenario(newClient(t, server.URL, recorder))
+	server.Close()
+	assert.NoError(t, recorder.Save())
+
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "secret-token")
+	assert.Contains(t, string(b), Redacted)
+
+	// Replay offline, against the closed server
+	replayer, err := New(path, ModeReplay)
+	assert.NoError(t, err)
+	assert.Len(t, replayer.Unused(), 4)
+
+	replayed := scenario(newClient(t, server.URL, replayer))
+
+	assert.Equal(t, recorded, replayed)
+	assert.Empty(t, replayer.Unused())
+
+	// Every interaction is replayed once
+	_, err = newClient(t, server.URL, replayer).Fetch(account.ID)
+	assert.True(t, errors.Is(err, ErrNoMatch), fmt.Sprintf("Want error %+v, but got %+v", ErrNoMatch, err))
+}
+
+func TestReplayMatching(t *testing.T) {
+	type testData struct {
+		method string
+		uri    string
+		body   string
+		err    error
+	}
+
+	var golds = []testData{
+		0: {http.MethodPost, "/v1/organisation/accounts", `{"data": {"id": "1"}}`, nil},
+		1: {http.MethodPost, "/v1/organisation/accounts", `{ "data":{"id":"1"} }`, nil},
+		2: {http.MethodPost, "/v1/organisation/accounts", `{"data": {"id": "2"}}`, ErrNoMatch},
+		3: {http.MethodPut, "/v1/organisation/accounts", `{"data": {"id": "1"}}`, ErrNoMatch},
+		4: {http.MethodGet, "/v1/organisation/accounts/1?version=0", "", ErrNoMatch},
+	}
+
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "matching.json")
+
+	cassette := `{"interactions": [
+		{"request": {"method": "POST", "url": "/v1/organisation/accounts", "body": "{\"data\":{\"id\":\"1\"}}"},
+		 "response": {"status_code": 201, "headers": {"Content-Type": ["application/json"]}, "body": "{}"}}
+	]}`
+	assert.NoError(t, ioutil.WriteFile(path, []byte(cassette), 0644))
+
+	for i, g := range golds {
+		r, err := New(path, ModeReplay)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(g.method, "http://localhost:8080"+g.uri, strings.NewReader(g.body))
+		assert.NoError(t, err)
+
+		resp, err := r.RoundTrip(req)
+
+		assert.True(t, errors.Is(err, g.err), fmt.Sprintf("%d. Want error %+v, but got %+v", i, g.err, err))
+		if g.err == nil {
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			body, _ := ioutil.ReadAll(resp.Body)
+			assert.Equal(t, "{}", string(body))
+		}
+	}
+}
+
+func TestRecordKeepsRequest(t *testing.T) {
+	var golds = []io.Reader{
+		// The body can be read again with GetBody
+		0: strings.NewReader(`{"data":{"id":"1"}}`),
+		// The body can be read only once
+		1: ioutil.NopCloser(strings.NewReader(`{"data":{"id":"1"}}`)),
+	}
+
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		received = append(received, string(b))
+		rw.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	for i, g := range golds {
+		r, err := New(filepath.Join("testdata", "unsaved.json"), ModeRecord)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/organisation/accounts", g)
+		assert.NoError(t, err)
+		body := req.Body
+
+		resp, err := r.RoundTrip(req)
+
+		assert.NoError(t, err, fmt.Sprintf("%d. Unexpected error", i))
+		assert.Equal(t, req, resp.Request, fmt.Sprintf("%d. Unexpected response request", i))
+		assert.True(t, body == req.Body, fmt.Sprintf("%d. The request body must not be replaced", i))
+		assert.Equal(t, `{"data":{"id":"1"}}`, received[i], fmt.Sprintf("%d. Unexpected sent body", i))
+		assert.Equal(t, `{"data":{"id":"1"}}`, r.cassette.Interactions[0].Request.Body, fmt.Sprintf("%d. Unexpected recorded body", i))
+	}
+}
+
+func TestMissingCassette(t *testing.T) {
+	_, err := New(filepath.Join("testdata", "missing.json"), ModeReplay)
+	assert.True(t, errors.Is(err, os.ErrNotExist), fmt.Sprintf("Want error %+v, but got %+v", os.ErrNotExist, err))
+}
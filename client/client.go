@@ -2,27 +2,57 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/google/go-querystring/query"
 	"io"
-	"log"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 )
 
-// Client is our consumer interface to the Accounts API.
+// Client is our consumer interface to the Accounts API. Use the function New() to create a configured Client.
 type Client struct {
-	BaseURL    *url.URL
-	httpClient *http.Client
+	BaseURL     *url.URL
+	httpClient  *http.Client
+	timeout     *time.Duration
+	userAgent   string
+	header      http.Header
+	retry       *RetryPolicy
+	limiter     *rateLimiter
+	validate    bool
+	logger      Logger
+	middlewares []Middleware
+	metrics     MetricsRecorder
+	tracer      Tracer
+	breaker     *circuitBreaker
 }
 
 // An AccountsResource is a wrapper around multiple Account values used for serialization.
+// When returned by List(), it allows navigating to the adjacent pages as advertised by the service in its links.
 type AccountsResource struct {
-	Data []Account `json:"data"`
+	Data  []Account `json:"data"`
+	Links *Links    `json:"links,omitempty"`
+	Meta  Meta      `json:"meta,omitempty"`
+
+	client *Client
+}
+
+// Links represents the JSON:API links of a paginated response.
+type Links struct {
+	Self  string `json:"self,omitempty"`
+	First string `json:"first,omitempty"`
+	Last  string `json:"last,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
 }
 
+// Meta represents the JSON:API non-standard meta-information of a response.
+type Meta map[string]interface{}
+
 // An AccountResource is a wrapper around a single Account value used for serialization.
 type AccountResource struct {
 	Data Account `json:"data"`
@@ -30,37 +60,78 @@ type AccountResource struct {
 
 // Account represents a bank account that is registered with Form3.
 type Account struct {
-	ID             string     `json:"id"`
-	OrganisationID string     `json:"organisation_id"`
-	Type           string     `json:"type"`
-	Version        int64      `json:"version"`
-	Attributes     Attributes `json:"attributes"`
+	ID             string       `json:"id"`
+	OrganisationID string       `json:"organisation_id"`
+	Type           ResourceType `json:"type"`
+	Version        int64        `json:"version"`
+	CreatedOn      *time.Time   `json:"created_on,omitempty"`
+	ModifiedOn     *time.Time   `json:"modified_on,omitempty"`
+	Attributes     Attributes   `json:"attributes"`
 }
 
 // Attributes represent the account attributes as per the Form3 specifications.
 type Attributes struct {
-	Country                     string   `json:"country"`
-	BaseCurrency                string   `json:"base_currency"`
-	BankID                      string   `json:"bank_id"`
-	BankIDCode                  string   `json:"bank_id_code"`
-	AccountNumber               string   `json:"account_number"`
-	BIC                         string   `json:"bic"`
-	IBAN                        string   `json:"iban"`
-	CustomerID                  string   `json:"customer_id"`
-	Title                       string   `json:"title"`
-	FirstName                   string   `json:"first_name"`
-	BankAccountName             string   `json:"bank_account_name"`
-	AlternativeBankAccountNames []string `json:"alternative_bank_account_names"`
-	AccountClassification       string   `json:"account_classification"`
-	JointAccount                bool     `json:"joint_account"`
-	AccountMatchingOptOut       bool     `json:"account_matching_opt_out"`
-	SecondaryIdentification     string   `json:"secondary_identification"`
-}
-
-// errorDetail represents an error in the response body returned by the service.
-type errorDetail struct {
-	ErrorCode string `json:"error_code"`
-	ErrorMsg  string `json:"error_message"`
+	Country                     Country               `json:"country"`
+	BaseCurrency                Currency              `json:"base_currency"`
+	BankID                      string                `json:"bank_id"`
+	BankIDCode                  BankIDCode            `json:"bank_id_code"`
+	AccountNumber               string                `json:"account_number"`
+	BIC                         string                `json:"bic"`
+	IBAN                        string                `json:"iban"`
+	CustomerID                  string                `json:"customer_id"`
+	Title                       string                `json:"title"`
+	FirstName                   string                `json:"first_name"`
+	BankAccountName             string                `json:"bank_account_name"`
+	AlternativeBankAccountNames []string              `json:"alternative_bank_account_names"`
+	AccountClassification       AccountClassification `json:"account_classification"`
+	JointAccount                bool                  `json:"joint_account"`
+	AccountMatchingOptOut       bool                  `json:"account_matching_opt_out"`
+	SecondaryIdentification     string                `json:"secondary_identification"`
+
+	// Name holds up to four lines of the account holder name. It replaces Title and FirstName.
+	Name                       []string                    `json:"name,omitempty"`
+	Status                     string                      `json:"status,omitempty"`
+	StatusReason               string                      `json:"status_reason,omitempty"`
+	Switched                   bool                        `json:"switched,omitempty"`
+	UserDefinedData            []UserDefinedData           `json:"user_defined_data,omitempty"`
+	ValidationType             string                      `json:"validation_type,omitempty"`
+	ReferenceMask              string                      `json:"reference_mask,omitempty"`
+	AcceptanceQualifier        string                      `json:"acceptance_qualifier,omitempty"`
+	ProcessingService          string                      `json:"processing_service,omitempty"`
+	PrivateIdentification      *PrivateIdentification      `json:"private_identification,omitempty"`
+	OrganisationIdentification *OrganisationIdentification `json:"organisation_identification,omitempty"`
+}
+
+// UserDefinedData represents a custom key-value pair stored along the account.
+type UserDefinedData struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// PrivateIdentification represents the identification of an account holder who is a person.
+type PrivateIdentification struct {
+	BirthDate      string   `json:"birth_date,omitempty"`
+	BirthCountry   string   `json:"birth_country,omitempty"`
+	Identification string   `json:"identification,omitempty"`
+	Address        []string `json:"address,omitempty"`
+	City           string   `json:"city,omitempty"`
+	Country        string   `json:"country,omitempty"`
+}
+
+// OrganisationIdentification represents the identification of an account holder which is an organisation.
+type OrganisationIdentification struct {
+	Identification string   `json:"identification,omitempty"`
+	Actors         []Actor  `json:"actors,omitempty"`
+	Address        []string `json:"address,omitempty"`
+	City           string   `json:"city,omitempty"`
+	Country        string   `json:"country,omitempty"`
+}
+
+// Actor represents a person acting on behalf of an organisation account holder.
+type Actor struct {
+	Name      []string `json:"name,omitempty"`
+	BirthDate string   `json:"birth_date,omitempty"`
+	Residency string   `json:"residency,omitempty"`
 }
 
 // PageOpts represents some paging options. Use it in the method List() to specify custom paging.
@@ -69,6 +140,51 @@ type PageOpts struct {
 	Size   PageSizeOpt `url:"page[size],omitempty"`
 }
 
+// AccountPatch represents the changes to apply to the attributes of an account. Use it in the method Update().
+// Only the non-nil fields are changed.
+type AccountPatch struct {
+	BankAccountName             *string                `json:"bank_account_name,omitempty"`
+	AlternativeBankAccountNames []string               `json:"alternative_bank_account_names,omitempty"`
+	Title                       *string                `json:"title,omitempty"`
+	FirstName                   *string                `json:"first_name,omitempty"`
+	CustomerID                  *string                `json:"customer_id,omitempty"`
+	AccountClassification       *AccountClassification `json:"account_classification,omitempty"`
+	JointAccount                *bool                  `json:"joint_account,omitempty"`
+	AccountMatchingOptOut       *bool                  `json:"account_matching_opt_out,omitempty"`
+	SecondaryIdentification     *string                `json:"secondary_identification,omitempty"`
+
+	Name            []string          `json:"name,omitempty"`
+	StatusReason    *string           `json:"status_reason,omitempty"`
+	UserDefinedData []UserDefinedData `json:"user_defined_data,omitempty"`
+}
+
+// accountPatchResource is a wrapper around an AccountPatch used for serialization.
+type accountPatchResource struct {
+	Data struct {
+		ID         string       `json:"id"`
+		Type       ResourceType `json:"type"`
+		Version    int64        `json:"version"`
+		Attributes AccountPatch `json:"attributes"`
+	} `json:"data"`
+}
+
+// ListOpts represents some listing options: paging and filtering. Use it in the method ListWithOpts().
+type ListOpts struct {
+	PageOpts
+	Filter *AccountFilter `url:"filter,omitempty"`
+}
+
+// AccountFilter represents the server-side filters of the listed accounts. Only the accounts matching all the non-empty
+// fields are listed.
+type AccountFilter struct {
+	BankID        string `url:"bank_id,omitempty"`
+	BankIDCode    string `url:"bank_id_code,omitempty"`
+	AccountNumber string `url:"account_number,omitempty"`
+	IBAN          string `url:"iban,omitempty"`
+	Country       string `url:"country,omitempty"`
+	CustomerID    string `url:"customer_id,omitempty"`
+}
+
 // PageNumOpt represents an optional page number. It should hold numeric values or the special values 'first' or 'last'
 type PageNumOpt *string
 
@@ -77,13 +193,41 @@ type PageSizeOpt *int64
 
 var (
 	// Errors
-	ErrNotFound    = errors.New("not found")
-	ErrConflict    = errors.New("conflict")
-	ErrBadInput    = errors.New("bad input")
-	ErrServerError = errors.New("internal server error")
-	ErrUnknown     = errors.New("unknown")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrBadInput     = errors.New("bad input")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrServerError  = errors.New("internal server error")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrUnknown      = errors.New("unknown")
+	ErrNoPage       = errors.New("no such page")
+	ErrSkipped      = errors.New("skipped")
+
+	// ErrCircuitOpen is returned without calling the Accounts API while the circuit breaker is open.
+	ErrCircuitOpen = errors.New("circuit open")
+
+	// ErrConflictingAccount is returned by CreateOrGet() when a different account already exists with the same ID.
+	ErrConflictingAccount = errors.New("conflicting account")
+
+	// Validation errors
+	ErrInvalidAccount = errors.New("invalid account")
+	ErrUnknownValue   = errors.New("unknown value")
+
+	// Configuration errors
+	ErrInvalidBaseURL = errors.New("invalid base URL")
+	ErrInvalidOption  = errors.New("invalid option")
+	ErrInvalidKey     = errors.New("invalid key")
+)
+
+// The route templates of the Accounts API, which identify the calls regardless of the account.
+const (
+	accountsRoute = "/v1/organisation/accounts"
+	accountRoute  = "/v1/organisation/accounts/{id}"
 )
 
+// defaultUserAgent is the User-Agent header sent by the Client unless overridden with WithUserAgent().
+const defaultUserAgent = "Accounts API Go client"
+
 // PageNumOptOf returns the typed PageNumOpt for the given value v.
 func PageNumOptOf(v string) PageNumOpt {
 	return &v
@@ -94,17 +238,43 @@ func PageSizeOptOf(v int64) PageSizeOpt {
 	return &v
 }
 
+// StringOf returns a pointer to the given value v. Use it to set the fields of an AccountPatch.
+func StringOf(v string) *string {
+	return &v
+}
+
+// BoolOf returns a pointer to the given value v. Use it to set the fields of an AccountPatch.
+func BoolOf(v bool) *bool {
+	return &v
+}
+
 // Create register the given bank account with Form3 or create a new one.
 func (c *Client) Create(account *AccountResource) (*AccountResource, error) {
+	return c.CreateContext(context.Background(), account)
+}
+
+// CreateContext is like Create, but it carries the given context ctx along the request.
+func (c *Client) CreateContext(ctx context.Context, account *AccountResource) (*AccountResource, error) {
 	path := fmt.Sprintf("/v1/organisation/accounts")
 
-	req, err := c.newRequest(http.MethodPost, path, "", account)
+	if c.validate && account != nil {
+		if err := account.Data.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, path, "", account)
 	if err != nil {
 		return nil, err
 	}
 
+	op := &operation{name: "create", route: accountsRoute, retryable: true}
+	if account != nil {
+		op.accountID = account.Data.ID
+	}
+
 	var created AccountResource
-	resp, err := c.do(req, &created)
+	resp, err := c.do(op, req, &created)
 	if err != nil {
 		return nil, err
 	}
@@ -113,32 +283,64 @@ func (c *Client) Create(account *AccountResource) (*AccountResource, error) {
 	case http.StatusCreated:
 		return &created, nil
 	case http.StatusConflict:
-		return nil, ErrConflict
-	default:
-		if resp.StatusCode >= http.StatusInternalServerError {
-			logError(resp)
-			return nil, ErrServerError
-		} else if resp.StatusCode >= http.StatusBadRequest {
-			logError(resp)
-			return nil, ErrBadInput
+		// The previous attempt may have created the account, but its response got lost on the way back. An internal
+		// server error may have happened after the account was persisted too, and the account is compared anyway.
+		mayHaveCreated := op.previousMayHaveApplied() || (op.attempts > 1 && op.lastStatus == http.StatusInternalServerError)
+		if mayHaveCreated && account != nil {
+			if existing, err := c.FetchContext(ctx, account.Data.ID); err == nil {
+				if diff, err := conflictingFields(account.Data, existing.Data); err == nil && len(diff) == 0 {
+					return existing, nil
+				}
+			}
 		}
-		// Unknown error (not according the specifications)
-		logError(resp)
-		return nil, ErrUnknown
+		return nil, newAPIError(resp, ErrConflict)
+	default:
+		return nil, unexpectedStatus(resp)
 	}
 }
 
+// CreateOrGet is like CreateContext, but it is idempotent: when an account already exists with the same ID, it fetches
+// that account and returns it, as long as it matches the given account. Otherwise, it returns a ConflictError carrying
+// the differing fields, which matches both ErrConflictingAccount and ErrConflict.
+// Only the fields set in the given account are compared, since the service may default the others.
+func (c *Client) CreateOrGet(ctx context.Context, account *AccountResource) (*AccountResource, error) {
+	created, err := c.CreateContext(ctx, account)
+	if !errors.Is(err, ErrConflict) || account == nil {
+		return created, err
+	}
+
+	existing, err := c.FetchContext(ctx, account.Data.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := conflictingFields(account.Data, existing.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(diff) > 0 {
+		return nil, &ConflictError{Existing: existing, Diff: diff}
+	}
+	return existing, nil
+}
+
 // Fetch fetches the account referenced by the given accountID.
 func (c *Client) Fetch(accountID string) (*AccountResource, error) {
+	return c.FetchContext(context.Background(), accountID)
+}
+
+// FetchContext is like Fetch, but it carries the given context ctx along the request.
+func (c *Client) FetchContext(ctx context.Context, accountID string) (*AccountResource, error) {
 	path := fmt.Sprintf("/v1/organisation/accounts/%s", accountID)
 
-	req, err := c.newRequest(http.MethodGet, path, "", nil)
+	req, err := c.newRequest(ctx, http.MethodGet, path, "", nil)
 	if err != nil {
 		return nil, err
 	}
 
 	var account AccountResource
-	resp, err := c.do(req, &account)
+	resp, err := c.do(&operation{name: "fetch", route: accountRoute, accountID: accountID, retryable: true}, req,
+		&account)
 	if err != nil {
 		return nil, err
 	}
@@ -147,70 +349,154 @@ func (c *Client) Fetch(accountID string) (*AccountResource, error) {
 	case http.StatusOK:
 		return &account, nil
 	case http.StatusNotFound:
-		return nil, ErrNotFound
+		return nil, newAPIError(resp, ErrNotFound)
 	default:
-		if resp.StatusCode >= http.StatusInternalServerError {
-			logError(resp)
-			return nil, ErrServerError
-		} else if resp.StatusCode >= http.StatusBadRequest {
-			logError(resp)
-			return nil, ErrBadInput
-		}
-		// Unknown error (not according the specifications)
-		logError(resp)
-		return nil, ErrUnknown
+		return nil, unexpectedStatus(resp)
 	}
 }
 
 // List lists all the presents accounts using the given paging options opts.
 func (c *Client) List(opts *PageOpts) (*AccountsResource, error) {
+	return c.ListContext(context.Background(), opts)
+}
 
-	// Resolve the query string (only paging support)
+// ListContext is like List, but it carries the given context ctx along the request.
+func (c *Client) ListContext(ctx context.Context, opts *PageOpts) (*AccountsResource, error) {
+	var listOpts ListOpts
+	if opts != nil {
+		listOpts.PageOpts = *opts
+	}
+	return c.ListWithOpts(ctx, &listOpts)
+}
+
+// ListWithOpts lists the accounts matching the filter of the given listing options opts, using their paging options.
+func (c *Client) ListWithOpts(ctx context.Context, opts *ListOpts) (*AccountsResource, error) {
+
+	// Resolve the query string (paging and filtering support)
 	qryString := ""
 	qryParams, _ := query.Values(opts)
 	if len(qryParams) > 0 {
 		qryString = qryParams.Encode()
 	}
 
-	req, err := c.newRequest(http.MethodGet, "/v1/organisation/accounts", qryString, nil)
+	return c.list(ctx, "/v1/organisation/accounts", qryString)
+}
+
+// HasNext reports whether the service advertised a next page.
+func (r *AccountsResource) HasNext() bool {
+	return r.Links != nil && r.Links.Next != ""
+}
+
+// HasPrev reports whether the service advertised a previous page.
+func (r *AccountsResource) HasPrev() bool {
+	return r.Links != nil && r.Links.Prev != ""
+}
+
+// NextPage lists the next page, as advertised by the service. It returns ErrNoPage if there is no such page.
+func (r *AccountsResource) NextPage(ctx context.Context) (*AccountsResource, error) {
+	if !r.HasNext() {
+		return nil, ErrNoPage
+	}
+	return r.follow(ctx, r.Links.Next)
+}
+
+// PrevPage lists the previous page, as advertised by the service. It returns ErrNoPage if there is no such page.
+func (r *AccountsResource) PrevPage(ctx context.Context) (*AccountsResource, error) {
+	if !r.HasPrev() {
+		return nil, ErrNoPage
+	}
+	return r.follow(ctx, r.Links.Prev)
+}
+
+// follow lists the page referenced by the given link. Only its path and query are used, so the request is always sent
+// to the base URL of the Client.
+func (r *AccountsResource) follow(ctx context.Context, link string) (*AccountsResource, error) {
+	if r.client == nil {
+		return nil, fmt.Errorf("%w: not listed by a client", ErrNoPage)
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid link %q. %s", ErrNoPage, link, err)
+	}
+
+	return r.client.list(ctx, u.Path, u.RawQuery)
+}
+
+func (c *Client) list(ctx context.Context, path, qryString string) (*AccountsResource, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, path, qryString, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	var accounts AccountsResource
-	resp, err := c.do(req, &accounts)
+	resp, err := c.do(&operation{name: "list", route: accountsRoute, retryable: true}, req, &accounts)
 	if err != nil {
 		return nil, err
 	}
 
 	switch resp.StatusCode {
 	case http.StatusOK:
+		accounts.client = c
 		return &accounts, nil
 	default:
-		if resp.StatusCode >= http.StatusInternalServerError {
-			logError(resp)
-			return nil, ErrServerError
-		} else if resp.StatusCode >= http.StatusBadRequest {
-			logError(resp)
-			return nil, ErrBadInput
-		}
-		// Unknown error (not according the specifications)
-		logError(resp)
-		return nil, ErrUnknown
+		return nil, unexpectedStatus(resp)
+	}
+}
+
+// Update applies the given patch to the account referenced by the given accountID and version. It returns ErrConflict
+// if the given version is not the current one, so that concurrent updates are not lost.
+// Update is never retried, since a lost response would make the retry conflict with the updated version.
+func (c *Client) Update(ctx context.Context, accountID string, version int64, patch AccountPatch) (*AccountResource, error) {
+	path := fmt.Sprintf("/v1/organisation/accounts/%s", accountID)
+
+	var body accountPatchResource
+	body.Data.ID = accountID
+	body.Data.Type = TypeAccounts
+	body.Data.Version = version
+	body.Data.Attributes = patch
+
+	req, err := c.newRequest(ctx, http.MethodPatch, path, "", &body)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated AccountResource
+	resp, err := c.do(&operation{name: "update", route: accountRoute, accountID: accountID}, req, &updated)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return &updated, nil
+	case http.StatusNotFound:
+		return nil, newAPIError(resp, ErrNotFound)
+	case http.StatusConflict:
+		return nil, newAPIError(resp, ErrConflict)
+	default:
+		return nil, unexpectedStatus(resp)
 	}
 }
 
 // Delete deletes an account referenced by the given accountID and version.
 func (c *Client) Delete(accountID string, version int64) error {
+	return c.DeleteContext(context.Background(), accountID, version)
+}
+
+// DeleteContext is like Delete, but it carries the given context ctx along the request.
+func (c *Client) DeleteContext(ctx context.Context, accountID string, version int64) error {
 	path := fmt.Sprintf("/v1/organisation/accounts/%s", accountID)
 	qryString := fmt.Sprintf("version=%d", version)
 
-	req, err := c.newRequest(http.MethodDelete, path, qryString, nil)
+	req, err := c.newRequest(ctx, http.MethodDelete, path, qryString, nil)
 	if err != nil {
 		return err
 	}
 
-	resp, err := c.do(req, nil)
+	op := &operation{name: "delete", route: accountRoute, accountID: accountID, retryable: true}
+
+	resp, err := c.do(op, req, nil)
 	if err != nil {
 		return err
 	}
@@ -219,24 +505,23 @@ func (c *Client) Delete(accountID string, version int64) error {
 	case http.StatusNoContent:
 		return nil
 	case http.StatusNotFound:
-		return ErrNotFound
+		// The previous attempt may have deleted the account, but its response got lost on the way back
+		if op.previousMayHaveApplied() {
+			return nil
+		}
+		return newAPIError(resp, ErrNotFound)
 	case http.StatusConflict:
-		return ErrConflict
+		return newAPIError(resp, ErrConflict)
 	default:
-		if resp.StatusCode >= http.StatusInternalServerError {
-			logError(resp)
-			return ErrServerError
-		} else if resp.StatusCode >= http.StatusBadRequest {
-			logError(resp)
-			return ErrBadInput
-		}
-		// Unknown error (not according the specifications)
-		logError(resp)
-		return ErrUnknown
+		return unexpectedStatus(resp)
 	}
 }
 
-func (c *Client) newRequest(method, path, qryString string, body interface{}) (*http.Request, error) {
+func (c *Client) newRequest(ctx context.Context, method, path, qryString string, body interface{}) (*http.Request, error) {
+	if c.BaseURL == nil {
+		return nil, fmt.Errorf("%w: base URL not set", ErrInvalidBaseURL)
+	}
+
 	rel := url.URL{Path: path}
 	if qryString != "" {
 		rel.RawQuery = qryString
@@ -252,53 +537,147 @@ func (c *Client) newRequest(method, path, qryString string, body interface{}) (*
 		}
 	}
 
-	req, err := http.NewRequest(method, u.String(), buf)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), buf)
 	if err != nil {
 		return nil, err
 	}
 
+	for k, values := range c.header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
 	if body != nil {
 		req.Header.Set("Content-Type", "application/vnd.api+json")
 	}
 
 	req.Header.Set("Accept", "application/vnd.api+json")
-	req.Header.Set("User-Agent", "Accounts API Go client")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	} else {
+		req.Header.Set("User-Agent", defaultUserAgent)
+	}
 
 	return req, nil
 }
 
-func (c *Client) do(req *http.Request, v interface{}) (*http.Response, error) {
-	if c.httpClient == nil {
-		c.httpClient = http.DefaultClient
+// do sends the given request req on behalf of the operation op, tracing it and recording its metrics, if enabled. The
+// body of a successful response is decoded into v.
+func (c *Client) do(op *operation, req *http.Request, v interface{}) (resp *http.Response, err error) {
+	if c.tracer != nil {
+		var span Span
+		req, span = c.startSpan(op, req)
+		defer func() {
+			endSpan(span, op, resp, err)
+		}()
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+	if c.metrics != nil {
+		c.metrics.InFlight(op.name, 1)
+		defer c.metrics.InFlight(op.name, -1)
+
+		start := time.Now()
+		defer func() {
+			c.metrics.ObserveCall(op.name, statusClassOf(resp, err), time.Since(start), op.attempts-1)
+		}()
 	}
 
-	if v != nil {
-		err = json.NewDecoder(resp.Body).Decode(&v)
+	return c.doWithRetries(op, req, v)
+}
+
+// doWithRetries sends the given request req on behalf of the operation op, retrying it according to the retry policy of
+// the Client. The body of a successful response is decoded into v.
+func (c *Client) doWithRetries(op *operation, req *http.Request, v interface{}) (*http.Response, error) {
+	policy := c.retryPolicyFor(op)
+
+	for {
+		op.attempts++
+
+		start := time.Now()
+		resp, err := c.attempt(req, v)
+		retrying := op.attempts < policy.MaxAttempts && policy.shouldRetry(resp, err)
+		var wait time.Duration
+		if retrying {
+			wait, retrying = policy.wait(req.Context(), op.attempts, resp)
+		}
+		c.logAttempt(op, req, resp, err, time.Since(start), retrying)
+		if !retrying {
+			return resp, err
+		}
+
+		op.lastStatus, op.lastErr = 0, err
+		if resp != nil {
+			op.lastStatus = resp.StatusCode
+		}
+
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		req, err = rewind(req)
 		if err != nil {
 			return nil, err
 		}
 	}
+}
 
-	err = resp.Body.Close()
-	if err != nil {
-		return resp, err
+// attempt sends the given request req once. The body of a successful response is decoded into v.
+func (c *Client) attempt(req *http.Request, v interface{}) (*http.Response, error) {
+	// Check the circuit breaker first, so that the calls fail fast while it is open
+	var generation uint64
+	if c.breaker != nil {
+		var err error
+		if generation, err = c.breaker.allow(); err != nil {
+			return nil, err
+		}
 	}
 
-	return resp, nil
-}
+	if c.limiter != nil {
+		if err := c.limiter.wait(req.Context()); err != nil {
+			if c.breaker != nil {
+				c.breaker.release(generation)
+			}
+			return nil, err
+		}
+	}
 
-func logError(resp *http.Response) {
-	var errDetail errorDetail
+	// Send a copy, so that the changes made by the middlewares do not leak into the next attempts
+	resp, err := c.doer().Do(req.Clone(req.Context()))
+	if c.breaker != nil {
+		c.breaker.done(generation, resp, contextErr(req.Context(), err))
+	}
+	if err != nil {
+		return nil, contextErr(req.Context(), err)
+	}
 
-	err := json.NewDecoder(resp.Body).Decode(&errDetail)
+	// Buffer the body, so it is still available to build an APIError once the connection is released
+	body, err := ioutil.ReadAll(resp.Body)
+	closeErr := resp.Body.Close()
 	if err != nil {
-		log.Printf("failed to decode error response. request: %s. error: %s\n", resp.Request.URL, err)
+		return nil, contextErr(req.Context(), err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if v != nil && resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		err = json.Unmarshal(body, &v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if closeErr != nil {
+		return resp, closeErr
 	}
 
-	log.Printf("failed to call Account api. request: %s. error: %s %s\n", resp.Request.URL.String(), errDetail.ErrorCode, errDetail.ErrorMsg)
+	return resp, nil
+}
+
+// contextErr returns the error of the given context ctx, if any, so that callers can match context.Canceled and
+// context.DeadlineExceeded. Otherwise, it returns the given err.
+func contextErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
 }
@@ -159,7 +159,7 @@ func TestPactCreate(t *testing.T) {
 			if a != nil {
 				return errors.New("account returned, but not expected")
 			}
-			if err != ErrConflict {
+			if !errors.Is(err, ErrConflict) {
 				return fmt.Errorf("error %s, but expected %s", err, ErrConflict)
 			}
 			return nil
@@ -199,7 +199,7 @@ func TestPactCreate(t *testing.T) {
 			if a != nil {
 				return errors.New("account returned, but not expected")
 			}
-			if err != ErrBadInput {
+			if !errors.Is(err, ErrBadInput) {
 				return fmt.Errorf("error %s, but expected %s", err, ErrBadInput)
 			}
 			return nil
@@ -229,7 +229,7 @@ func TestPactCreate(t *testing.T) {
 			if a != nil {
 				return errors.New("account returned, but not expected")
 			}
-			if err != ErrServerError {
+			if !errors.Is(err, ErrServerError) {
 				return fmt.Errorf("error %s, but expected %s", err, ErrServerError)
 			}
 			return nil
@@ -299,7 +299,7 @@ func TestPactFetch(t *testing.T) {
 			if a != nil {
 				return errors.New("account fetched, but not expected")
 			}
-			if err != ErrNotFound {
+			if !errors.Is(err, ErrNotFound) {
 				return fmt.Errorf("error %s, but expected %s", err, ErrNotFound)
 			}
 			return nil
@@ -337,7 +337,7 @@ func TestPactFetch(t *testing.T) {
 			if a != nil {
 				return errors.New("account fetched, but not expected")
 			}
-			if err != ErrBadInput {
+			if !errors.Is(err, ErrBadInput) {
 				return fmt.Errorf("error %s, but expected %s", err, ErrBadInput)
 			}
 			return nil
@@ -368,7 +368,7 @@ func TestPactFetch(t *testing.T) {
 			if a != nil {
 				return errors.New("account fetched, but not expected")
 			}
-			if err != ErrServerError {
+			if !errors.Is(err, ErrServerError) {
 				return fmt.Errorf("error %s, but expected %s", err, ErrServerError)
 			}
 			return nil
@@ -492,7 +492,7 @@ func TestPactList(t *testing.T) {
 			if accounts != nil {
 				return errors.New("accounts listed, but not expected")
 			}
-			if err != ErrBadInput {
+			if !errors.Is(err, ErrBadInput) {
 				return fmt.Errorf("error %s, but expected %s", err, ErrBadInput)
 			}
 			return nil
@@ -537,7 +537,7 @@ func TestPactList(t *testing.T) {
 			if accounts != nil {
 				return errors.New("accounts listed, but not expected")
 			}
-			if err != ErrServerError {
+			if !errors.Is(err, ErrServerError) {
 				return fmt.Errorf("error %s, but expected %s", err, ErrServerError)
 			}
 			return nil
@@ -601,7 +601,7 @@ func TestPactDelete(t *testing.T) {
 		err := pact.Verify(func() error {
 			err := client.Delete(ID, 0)
 
-			if err != ErrConflict {
+			if !errors.Is(err, ErrConflict) {
 				return fmt.Errorf("error %s, but expected %s", err, ErrConflict)
 			}
 
@@ -629,7 +629,7 @@ func TestPactDelete(t *testing.T) {
 		err := pact.Verify(func() error {
 			err := client.Delete(ID, 0)
 
-			if err != ErrNotFound {
+			if !errors.Is(err, ErrNotFound) {
 				return fmt.Errorf("error %s, but expected %s", err, ErrNotFound)
 			}
 
@@ -667,7 +667,7 @@ func TestPactDelete(t *testing.T) {
 		err := pact.Verify(func() error {
 			err := client.Delete(notAGuiID, 0)
 
-			if err != ErrBadInput {
+			if !errors.Is(err, ErrBadInput) {
 				return fmt.Errorf("error %s, but expected %s", err, ErrBadInput)
 			}
 
@@ -695,7 +695,7 @@ func TestPactDelete(t *testing.T) {
 		err := pact.Verify(func() error {
 			err := client.Delete(ID, 0)
 
-			if err != ErrServerError {
+			if !errors.Is(err, ErrServerError) {
 				return fmt.Errorf("error %s, but expected %s", err, ErrServerError)
 			}
 
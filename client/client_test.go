@@ -3,7 +3,10 @@
 package client
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -13,6 +16,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -28,7 +32,7 @@ var (
 			BankID:          "400300",
 			BankIDCode:      "GBDSC",
 			BIC:             "NWBKGB22",
-			IBAN:            "GB11NWBK40030041426819",
+			IBAN:            "GB16NWBK40030041426819",
 			Title:           "Ms",
 			FirstName:       "Samantha",
 			BankAccountName: "Samantha Holder",
@@ -54,7 +58,7 @@ var (
 			BankID:          "400300",
 			BankIDCode:      "GBDSC",
 			BIC:             "NWBKGB22",
-			IBAN:            "GB11NWBK40030041426820",
+			IBAN:            "GB86NWBK40030041426820",
 			Title:           "Mr",
 			FirstName:       "Francisco",
 			BankAccountName: "Fernandez",
@@ -80,7 +84,7 @@ var (
 			BankID:          "400300",
 			BankIDCode:      "GBDSC",
 			BIC:             "NWBKGB22",
-			IBAN:            "GB11NWBK40030041426821",
+			IBAN:            "GB59NWBK40030041426821",
 			Title:           "Ms",
 			FirstName:       "Liza",
 			BankAccountName: "Johnson",
@@ -179,7 +183,7 @@ func TestCreate(t *testing.T) {
 		got, err := client.Create(&data.account)
 
 		assert.Equal(t, data.want, got, fmt.Sprintf("%d. Want account %+v, but got %+v", tc, data.want, got))
-		assert.Equal(t, data.err, err, fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, err))
+		assert.True(t, errors.Is(err, data.err), fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, err))
 	}
 
 	for i, g := range golds {
@@ -187,6 +191,142 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestCreateOrGet(t *testing.T) {
+	type testData struct {
+		existing []Account
+		account  AccountResource
+		want     *AccountResource
+		diff     []FieldDiff
+		err      error
+	}
+
+	renamed := accountOne
+	renamed.Attributes.BankAccountName = "Samantha Renamed"
+	renamed.Attributes.AccountMatchingOptOut = true
+
+	joint := accountOne
+	joint.Attributes.JointAccount = true
+
+	var golds = []testData{
+		0: {
+			[]Account{},
+			AccountResource{Data: accountOne},
+			&AccountResource{Data: accountOne},
+			nil,
+			nil,
+		},
+		1: {
+			[]Account{accountOne},
+			AccountResource{Data: accountOne},
+			&AccountResource{Data: accountOne},
+			nil,
+			nil,
+		},
+		2: {
+			[]Account{accountOne},
+			AccountResource{Data: renamed},
+			nil,
+			[]FieldDiff{
+				{"attributes.account_matching_opt_out", true, false},
+				{"attributes.bank_account_name", "Samantha Renamed", "Samantha Holder"},
+			},
+			ErrConflictingAccount,
+		},
+		3: {
+			[]Account{},
+			AccountResource{Data: createBadAccount()},
+			nil,
+			nil,
+			ErrBadInput,
+		},
+		4: {
+			[]Account{joint},
+			AccountResource{Data: accountOne},
+			nil,
+			[]FieldDiff{
+				{"attributes.joint_account", false, true},
+			},
+			ErrConflictingAccount,
+		},
+	}
+
+	var testCreateOrGet = func(t *testing.T, tc int, data testData) {
+		// Setup mocked account repository
+		repo := setupAccountRepo(data.existing...)
+
+		// Setup mocked server
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodGet {
+				pathSegments := strings.Split(req.URL.Path, "/")
+				serveContent(t, rw, http.StatusOK, AccountResource{Data: repo[pathSegments[len(pathSegments)-1]]})
+				return
+			}
+
+			var a AccountResource
+			err := json.NewDecoder(req.Body).Decode(&a)
+			assert.NoError(t, err)
+
+			if a.Data.ID == badInput {
+				serveError(t, rw, http.StatusBadRequest)
+			} else if _, ok := repo[a.Data.ID]; ok {
+				serveError(t, rw, http.StatusConflict)
+			} else {
+				serveContent(t, rw, http.StatusCreated, a)
+			}
+		}))
+		defer server.Close()
+
+		// Setup client
+		client := setupClient(t, server.URL)
+
+		got, err := client.CreateOrGet(context.Background(), &data.account)
+
+		assert.Equal(t, data.want, got, fmt.Sprintf("%d. Want account %+v, but got %+v", tc, data.want, got))
+		assert.True(t, errors.Is(err, data.err), fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, err))
+
+		var conflictErr *ConflictError
+		if errors.As(err, &conflictErr) {
+			assert.True(t, errors.Is(err, ErrConflict))
+			assert.Equal(t, data.diff, conflictErr.Diff, fmt.Sprintf("%d. Unexpected diff", tc))
+			assert.Equal(t, &AccountResource{Data: data.existing[0]}, conflictErr.Existing)
+		}
+	}
+
+	for i, g := range golds {
+		testCreateOrGet(t, i, g)
+	}
+}
+
+func TestCreateOrGetUnknownValue(t *testing.T) {
+	// The service holds a classification which is unknown to the client
+	existing := accountOne
+	existing.Attributes.AccountClassification = "Corporate"
+
+	body, err := json.Marshal(AccountResource{Data: accountOne})
+	assert.NoError(t, err)
+	body = bytes.Replace(body, []byte(`"account_classification":"Personal"`), []byte(`"account_classification":"Corporate"`), 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			_, err := rw.Write(body)
+			assert.NoError(t, err)
+			return
+		}
+		serveError(t, rw, http.StatusConflict)
+	}))
+	defer server.Close()
+
+	client := setupClient(t, server.URL)
+
+	got, err := client.CreateOrGet(context.Background(), &AccountResource{Data: accountOne})
+
+	assert.Nil(t, got)
+	var conflictErr *ConflictError
+	assert.True(t, errors.As(err, &conflictErr), fmt.Sprintf("Want error %+v, but got %+v", ErrConflictingAccount, err))
+	assert.Equal(t, []FieldDiff{{"attributes.account_classification", "Personal", "Corporate"}}, conflictErr.Diff)
+	assert.Equal(t, &AccountResource{Data: existing}, conflictErr.Existing)
+}
+
 func TestFetch(t *testing.T) {
 	type testData struct {
 		existing  []Account
@@ -251,7 +391,7 @@ func TestFetch(t *testing.T) {
 		got, err := client.Fetch(data.accountID)
 
 		assert.Equal(t, data.want, got, fmt.Sprintf("%d. Want account %+v, but got %+v", tc, data.want, got))
-		assert.Equal(t, data.err, err, fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, err))
+		assert.True(t, errors.Is(err, data.err), fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, err))
 	}
 
 	for i, g := range golds {
@@ -499,8 +639,13 @@ func TestList(t *testing.T) {
 
 		got, err := client.List(&data.opts)
 
+		// The listed accounts are bound to the client, so that they can navigate to the adjacent pages
+		if data.want != nil {
+			data.want.client = client
+		}
+
 		assert.Equal(t, data.want, got, fmt.Sprintf("%d. Want accounts %+v, but got %+v", tc, data.want, got))
-		assert.Equal(t, data.err, err, fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, err))
+		assert.True(t, errors.Is(err, data.err), fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, err))
 	}
 
 	for i, g := range golds {
@@ -508,6 +653,213 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestListLinks(t *testing.T) {
+	pages := map[string]AccountsResource{
+		"0": {
+			Data: []Account{accountOne},
+			Links: &Links{
+				Self:  "/v1/organisation/accounts?page[number]=0&page[size]=1",
+				First: "/v1/organisation/accounts?page[number]=first&page[size]=1",
+				Last:  "/v1/organisation/accounts?page[number]=last&page[size]=1",
+				Next:  "/v1/organisation/accounts?page[number]=1&page[size]=1",
+			},
+			Meta: Meta{"total": float64(2)},
+		},
+		"1": {
+			Data: []Account{accountTwo},
+			Links: &Links{
+				Self:  "/v1/organisation/accounts?page[number]=1&page[size]=1",
+				First: "/v1/organisation/accounts?page[number]=first&page[size]=1",
+				Last:  "/v1/organisation/accounts?page[number]=last&page[size]=1",
+				// An absolute link must be sent to the base URL of the client anyway
+				Prev: "http://elsewhere.example.com/v1/organisation/accounts?page[number]=0&page[size]=1",
+			},
+		},
+	}
+
+	// Setup mocked server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/v1/organisation/accounts", req.URL.Path)
+		assert.Equal(t, "1", req.URL.Query().Get("page[size]"))
+
+		page, ok := pages[req.URL.Query().Get("page[number]")]
+		assert.True(t, ok, fmt.Sprintf("Unexpected page request %s", req.URL))
+		serveContent(t, rw, http.StatusOK, page)
+	}))
+	defer server.Close()
+
+	// Setup client
+	client := setupClient(t, server.URL)
+	ctx := context.Background()
+
+	first, err := client.List(&PageOpts{Number: PageNumOptOf("0"), Size: PageSizeOptOf(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, []Account{accountOne}, first.Data)
+	assert.Equal(t, pages["0"].Links, first.Links)
+	assert.Equal(t, Meta{"total": float64(2)}, first.Meta)
+	assert.True(t, first.HasNext())
+	assert.False(t, first.HasPrev())
+
+	_, err = first.PrevPage(ctx)
+	assert.True(t, errors.Is(err, ErrNoPage), fmt.Sprintf("Want error %+v, but got %+v", ErrNoPage, err))
+
+	second, err := first.NextPage(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Account{accountTwo}, second.Data)
+	assert.False(t, second.HasNext())
+	assert.True(t, second.HasPrev())
+
+	_, err = second.NextPage(ctx)
+	assert.True(t, errors.Is(err, ErrNoPage), fmt.Sprintf("Want error %+v, but got %+v", ErrNoPage, err))
+
+	prev, err := second.PrevPage(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Account{accountOne}, prev.Data)
+
+	// A resource not listed by a client cannot navigate
+	_, err = (&AccountsResource{Links: &Links{Next: "/v1/organisation/accounts"}}).NextPage(ctx)
+	assert.True(t, errors.Is(err, ErrNoPage), fmt.Sprintf("Want error %+v, but got %+v", ErrNoPage, err))
+}
+
+func TestUpdate(t *testing.T) {
+	type testData struct {
+		existing  []Account
+		accountID string
+		version   int64
+		patch     AccountPatch
+		want      *AccountResource
+		err       error
+	}
+
+	renamed := accountOne
+	renamed.Version = 1
+	renamed.Attributes.BankAccountName = "Samantha Smith"
+	renamed.Attributes.JointAccount = true
+
+	reclassified := accountOne
+	reclassified.Version = 1
+	reclassified.Attributes.AccountClassification = ClassificationBusiness
+
+	business, unknown := ClassificationBusiness, AccountClassification("business")
+
+	var golds = []testData{
+		0: {
+			[]Account{accountOne},
+			accountOne.ID,
+			accountOne.Version,
+			AccountPatch{BankAccountName: StringOf("Samantha Smith"), JointAccount: BoolOf(true)},
+			&AccountResource{Data: renamed},
+			nil,
+		},
+		1: {
+			[]Account{accountOne},
+			accountOne.ID,
+			100,
+			AccountPatch{BankAccountName: StringOf("Samantha Smith")},
+			nil,
+			ErrConflict,
+		},
+		2: {
+			[]Account{},
+			accountOne.ID,
+			accountOne.Version,
+			AccountPatch{BankAccountName: StringOf("Samantha Smith")},
+			nil,
+			ErrNotFound,
+		},
+		3: {
+			[]Account{},
+			badInput,
+			accountOne.Version,
+			AccountPatch{},
+			nil,
+			ErrBadInput,
+		},
+		4: {
+			[]Account{},
+			serviceFailure,
+			accountOne.Version,
+			AccountPatch{},
+			nil,
+			ErrServerError,
+		},
+		5: {
+			[]Account{accountOne},
+			accountOne.ID,
+			accountOne.Version,
+			AccountPatch{AccountClassification: &business},
+			&AccountResource{Data: reclassified},
+			nil,
+		},
+		6: {
+			[]Account{accountOne},
+			accountOne.ID,
+			accountOne.Version,
+			AccountPatch{AccountClassification: &unknown},
+			nil,
+			ErrUnknownValue,
+		},
+	}
+
+	var testUpdate = func(t *testing.T, tc int, data testData) {
+		// Setup mocked account repository
+		repo := setupAccountRepo(data.existing...)
+
+		// Setup mocked server
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			pathSegments := strings.Split(req.URL.Path, "/")
+			ID := pathSegments[len(pathSegments)-1]
+
+			assert.Equal(t, http.MethodPatch, req.Method)
+			assert.Equal(t, fmt.Sprintf("/v1/organisation/accounts/%s", ID), req.URL.String())
+			assert.Equal(t, "application/vnd.api+json", req.Header.Get("Content-Type"))
+
+			var patch accountPatchResource
+			err := json.NewDecoder(req.Body).Decode(&patch)
+			assert.NoError(t, err)
+			assert.Equal(t, ID, patch.Data.ID)
+			assert.Equal(t, TypeAccounts, patch.Data.Type)
+
+			if ID == badInput {
+				serveError(t, rw, http.StatusBadRequest)
+			} else if ID == serviceFailure {
+				serveError(t, rw, http.StatusInternalServerError)
+			} else if a, ok := repo[ID]; ok {
+				if a.Version != patch.Data.Version {
+					serveError(t, rw, http.StatusConflict)
+					return
+				}
+				if p := patch.Data.Attributes.BankAccountName; p != nil {
+					a.Attributes.BankAccountName = *p
+				}
+				if p := patch.Data.Attributes.JointAccount; p != nil {
+					a.Attributes.JointAccount = *p
+				}
+				if p := patch.Data.Attributes.AccountClassification; p != nil {
+					a.Attributes.AccountClassification = *p
+				}
+				a.Version++
+				serveContent(t, rw, http.StatusOK, AccountResource{Data: a})
+			} else {
+				serveError(t, rw, http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		// Setup client
+		client := setupClient(t, server.URL)
+
+		got, err := client.Update(context.Background(), data.accountID, data.version, data.patch)
+
+		assert.Equal(t, data.want, got, fmt.Sprintf("%d. Want account %+v, but got %+v", tc, data.want, got))
+		assert.True(t, errors.Is(err, data.err), fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, err))
+	}
+
+	for i, g := range golds {
+		testUpdate(t, i, g)
+	}
+}
+
 func TestDelete(t *testing.T) {
 	type testData struct {
 		existing  []Account
@@ -572,7 +924,7 @@ func TestDelete(t *testing.T) {
 
 		err := client.Delete(data.accountID, data.version)
 
-		assert.Equal(t, data.err, err, fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, err))
+		assert.True(t, errors.Is(err, data.err), fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, err))
 	}
 
 	for i, g := range golds {
@@ -580,6 +932,92 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestContext(t *testing.T) {
+	type testData struct {
+		ctx  func() (context.Context, context.CancelFunc)
+		call func(ctx context.Context, c *Client) error
+		err  error
+	}
+
+	var (
+		canceled = func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx, cancel
+		}
+		expired = func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 50*time.Millisecond)
+		}
+	)
+
+	var golds = []testData{
+		0: {
+			canceled,
+			func(ctx context.Context, c *Client) error {
+				_, err := c.CreateContext(ctx, &AccountResource{Data: accountOne})
+				return err
+			},
+			context.Canceled,
+		},
+		1: {
+			expired,
+			func(ctx context.Context, c *Client) error {
+				_, err := c.FetchContext(ctx, accountOne.ID)
+				return err
+			},
+			context.DeadlineExceeded,
+		},
+		2: {
+			expired,
+			func(ctx context.Context, c *Client) error {
+				_, err := c.ListContext(ctx, &PageOpts{})
+				return err
+			},
+			context.DeadlineExceeded,
+		},
+		3: {
+			canceled,
+			func(ctx context.Context, c *Client) error {
+				return c.DeleteContext(ctx, accountOne.ID, accountOne.Version)
+			},
+			context.Canceled,
+		},
+	}
+
+	var testContext = func(t *testing.T, tc int, data testData) {
+		release := make(chan struct{})
+
+		// Setup mocked server which sends a partial body and then hangs until the test is over
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+			_, err := rw.Write([]byte(`{"data": `))
+			assert.NoError(t, err)
+			rw.(http.Flusher).Flush()
+
+			select {
+			case <-release:
+			case <-req.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		// Setup client
+		client := setupClient(t, server.URL)
+
+		ctx, cancel := data.ctx()
+		defer cancel()
+
+		err := data.call(ctx, client)
+
+		assert.True(t, errors.Is(err, data.err), fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, err))
+	}
+
+	for i, g := range golds {
+		testContext(t, i, g)
+	}
+}
+
 func setupClient(t *testing.T, baseURL string) *Client {
 	u, err := url.Parse(baseURL)
 	if err != nil {
@@ -635,3 +1073,182 @@ func createMonkeyAccount() Account {
 		ID: serviceFailure,
 	}
 }
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		serveError(t, rw, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := setupClient(t, server.URL)
+
+	_, err := client.Fetch(badInput)
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr), fmt.Sprintf("Want an APIError, but got %+v", err))
+	assert.True(t, errors.Is(err, ErrBadInput))
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "400", apiErr.ErrorCode)
+	assert.Equal(t, http.StatusText(http.StatusBadRequest), apiErr.ErrorMessage)
+	assert.Equal(t, http.MethodGet, apiErr.Method)
+	assert.Equal(t, fmt.Sprintf("%s/v1/organisation/accounts/%s", server.URL, badInput), apiErr.URL)
+	assert.JSONEq(t, `{"error_code": "400", "error_message": "Bad Request"}`, string(apiErr.Body))
+}
+
+func TestAPIErrorUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		serveError(t, rw, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := setupClient(t, server.URL)
+
+	_, err := client.Fetch(accountOne.ID)
+
+	// The unauthorized errors still match ErrBadInput
+	assert.True(t, errors.Is(err, ErrUnauthorized), fmt.Sprintf("Want error %+v, but got %+v", ErrUnauthorized, err))
+	assert.True(t, errors.Is(err, ErrBadInput), fmt.Sprintf("Want error %+v, but got %+v", ErrBadInput, err))
+	assert.False(t, errors.Is(err, ErrNotFound))
+}
+
+func TestListWithOpts(t *testing.T) {
+	type testData struct {
+		opts *ListOpts
+		want string
+	}
+
+	var golds = []testData{
+		0: {nil, ""},
+		1: {&ListOpts{}, ""},
+		2: {
+			&ListOpts{PageOpts: PageOpts{Number: PageNumOptOf("1"), Size: PageSizeOptOf(10)}},
+			"page[number]=1&page[size]=10",
+		},
+		3: {
+			&ListOpts{Filter: &AccountFilter{IBAN: "GB11NWBK40030041426819"}},
+			"filter[iban]=GB11NWBK40030041426819",
+		},
+		4: {
+			&ListOpts{
+				PageOpts: PageOpts{Size: PageSizeOptOf(10)},
+				Filter: &AccountFilter{
+					BankID:        "400300",
+					BankIDCode:    "GBDSC",
+					AccountNumber: "41426819",
+					Country:       "GB",
+					CustomerID:    "123",
+				},
+			},
+			"filter[account_number]=41426819&filter[bank_id]=400300&filter[bank_id_code]=GBDSC&filter[country]=GB&" +
+				"filter[customer_id]=123&page[size]=10",
+		},
+	}
+
+	for i, g := range golds {
+		var got string
+
+		// Setup mocked server
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			got, _ = url.QueryUnescape(req.URL.RawQuery)
+			serveContent(t, rw, http.StatusOK, AccountsResource{Data: []Account{accountOne}})
+		}))
+
+		// Setup client
+		client := setupClient(t, server.URL)
+
+		accounts, err := client.ListWithOpts(context.Background(), g.opts)
+		server.Close()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Account{accountOne}, accounts.Data)
+		assert.Equal(t, g.want, got, fmt.Sprintf("%d. Want query %s, but got %s", i, g.want, got))
+	}
+}
+
+func TestAccountJSON(t *testing.T) {
+	payload := `{
+		"data": {
+			"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+			"organisation_id": "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+			"type": "accounts",
+			"version": 2,
+			"created_on": "2020-03-10T10:15:30.123Z",
+			"modified_on": "2020-03-11T08:00:00Z",
+			"attributes": {
+				"country": "GB",
+				"base_currency": "GBP",
+				"bank_id": "400300",
+				"bank_id_code": "GBDSC",
+				"account_number": "41426819",
+				"bic": "NWBKGB22",
+				"iban": "GB11NWBK40030041426819",
+				"name": ["Samantha Holder"],
+				"status": "confirmed",
+				"status_reason": "unspecified",
+				"switched": true,
+				"user_defined_data": [{"key": "segment", "value": "retail"}],
+				"validation_type": "card",
+				"reference_mask": "############",
+				"acceptance_qualifier": "same_day",
+				"processing_service": "ABC Bank",
+				"private_identification": {
+					"birth_date": "2017-07-23",
+					"birth_country": "GB",
+					"identification": "13YH458762",
+					"address": ["10 Avenue des Champs"],
+					"city": "London",
+					"country": "GB"
+				},
+				"organisation_identification": {
+					"identification": "123654",
+					"actors": [{"name": ["Jeff Page"], "birth_date": "1970-01-01", "residency": "GB"}],
+					"address": ["10 Avenue des Champs"],
+					"city": "London",
+					"country": "GB"
+				}
+			}
+		}
+	}`
+
+	var got AccountResource
+	assert.NoError(t, json.Unmarshal([]byte(payload), &got))
+
+	createdOn := time.Date(2020, 3, 10, 10, 15, 30, 123000000, time.UTC)
+	modifiedOn := time.Date(2020, 3, 11, 8, 0, 0, 0, time.UTC)
+	assert.True(t, createdOn.Equal(*got.Data.CreatedOn), fmt.Sprintf("Want created on %s, but got %s", createdOn, got.Data.CreatedOn))
+	assert.True(t, modifiedOn.Equal(*got.Data.ModifiedOn), fmt.Sprintf("Want modified on %s, but got %s", modifiedOn, got.Data.ModifiedOn))
+
+	attrs := got.Data.Attributes
+	assert.Equal(t, []string{"Samantha Holder"}, attrs.Name)
+	assert.Equal(t, "confirmed", attrs.Status)
+	assert.Equal(t, "unspecified", attrs.StatusReason)
+	assert.True(t, attrs.Switched)
+	assert.Equal(t, []UserDefinedData{{Key: "segment", Value: "retail"}}, attrs.UserDefinedData)
+	assert.Equal(t, "card", attrs.ValidationType)
+	assert.Equal(t, "############", attrs.ReferenceMask)
+	assert.Equal(t, "same_day", attrs.AcceptanceQualifier)
+	assert.Equal(t, "ABC Bank", attrs.ProcessingService)
+	assert.Equal(t, &PrivateIdentification{
+		BirthDate:      "2017-07-23",
+		BirthCountry:   "GB",
+		Identification: "13YH458762",
+		Address:        []string{"10 Avenue des Champs"},
+		City:           "London",
+		Country:        "GB",
+	}, attrs.PrivateIdentification)
+	assert.Equal(t, &OrganisationIdentification{
+		Identification: "123654",
+		Actors:         []Actor{{Name: []string{"Jeff Page"}, BirthDate: "1970-01-01", Residency: "GB"}},
+		Address:        []string{"10 Avenue des Champs"},
+		City:           "London",
+		Country:        "GB",
+	}, attrs.OrganisationIdentification)
+
+	// The optional fields left unset are not sent
+	b, err := json.Marshal(AccountResource{Data: accountOne})
+	assert.NoError(t, err)
+	for _, field := range []string{"created_on", "modified_on", "name", "status", "switched", "user_defined_data",
+		"private_identification", "organisation_identification"} {
+		assert.NotContains(t, string(b), fmt.Sprintf("%q", field))
+	}
+}
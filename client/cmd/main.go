@@ -4,7 +4,8 @@ import (
 	"encoding/json"
 	client2 "gitlab.com/kitolabs-private/form3/interview-accountapi/client"
 	"log"
-	"net/url"
+	"os"
+	"time"
 )
 
 // Small application to show the client running against the Accounts API deployed in the docker-compose containers.
@@ -58,40 +59,29 @@ func delete(c *client2.Client, accountID string) {
 }
 
 func setupClient(baseUrl string) *client2.Client {
-	u, _ := url.Parse(baseUrl)
-	return &client2.Client{
-		BaseURL: u,
+	c, err := client2.New(baseUrl,
+		client2.WithTimeout(10*time.Second),
+		client2.WithLogger(client2.NewStdLogger(log.New(os.Stderr, "", log.LstdFlags))))
+	if err != nil {
+		log.Fatalf("failed to setup client. %s", err)
 	}
+	return c
 }
 
 func sampleAccount(account *client2.AccountResource) {
-	*account = client2.AccountResource{
-		Data: client2.Account{
-			ID:             "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
-			OrganisationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
-			Type:           "accounts",
-			Version:        0,
-			Attributes: client2.Attributes{
-				Country:         "GB",
-				BaseCurrency:    "GBP",
-				AccountNumber:   "41426819",
-				BankID:          "400300",
-				BankIDCode:      "GBDSC",
-				BIC:             "NWBKGB22",
-				IBAN:            "GB11NWBK40030041426819",
-				Title:           "Ms",
-				FirstName:       "Samantha",
-				BankAccountName: "Samantha Holder",
-				AlternativeBankAccountNames: []string{
-					"Sam Holder",
-				},
-				AccountClassification:   "Personal",
-				JointAccount:            false,
-				AccountMatchingOptOut:   false,
-				SecondaryIdentification: "A1B2C3D4",
-			},
-		},
+	a, err := client2.NewUKAccount("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c").
+		WithID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc").
+		WithSortCode("400300").
+		WithAccountNumber("41426819").
+		WithBIC("NWBKGB22").
+		WithName("Samantha Holder").
+		WithBankAccountName("Samantha Holder", "Sam Holder").
+		WithSecondaryIdentification("A1B2C3D4").
+		Build()
+	if err != nil {
+		log.Fatalf("failed to build sample account. %s", err)
 	}
+	*account = *a
 }
 
 func prettyPrintedJSON(v interface{}) string {
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// FieldDiff represents a field whose value differs between two accounts, named after its JSON path
+// (e.g. "attributes.bank_id"). The attribute values are given as decoded from JSON.
+type FieldDiff struct {
+	Field string
+	Want  interface{}
+	Got   interface{}
+}
+
+// conflictingFields returns the fields set in the wanted account want which differ in the got account, sorted by name.
+// The fields left unset in want (i.e. zero values) are ignored, since the service may default them. The boolean fields
+// are always compared though, since false cannot be told apart from unset.
+func conflictingFields(want, got Account) ([]FieldDiff, error) {
+	var diffs []FieldDiff
+
+	if want.OrganisationID != got.OrganisationID {
+		diffs = append(diffs, FieldDiff{"organisation_id", want.OrganisationID, got.OrganisationID})
+	}
+	if want.Type != "" && want.Type != got.Type {
+		diffs = append(diffs, FieldDiff{"type", want.Type, got.Type})
+	}
+
+	wantAttrs, err := attributesOf(want.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	gotAttrs, err := attributesOf(got.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range wantAttrs {
+		if isZero(v) {
+			continue
+		}
+		if !reflect.DeepEqual(v, gotAttrs[k]) {
+			diffs = append(diffs, FieldDiff{"attributes." + k, v, gotAttrs[k]})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Field < diffs[j].Field
+	})
+	return diffs, nil
+}
+
+// plainAttributes represents Attributes with its enumerated fields as plain strings, so that the values unknown to the
+// client (e.g. decoded from a newer service) can be serialized too. Its boolean fields are always serialized, so that
+// false is compared as any other value.
+type plainAttributes struct {
+	Attributes
+	Country               string `json:"country"`
+	BaseCurrency          string `json:"base_currency"`
+	BankIDCode            string `json:"bank_id_code"`
+	AccountClassification string `json:"account_classification"`
+	Switched              bool   `json:"switched"`
+}
+
+// attributesOf returns the given attributes attrs as they are serialized, keyed by their JSON name.
+func attributesOf(attrs Attributes) (map[string]interface{}, error) {
+	plain := plainAttributes{
+		Attributes:            attrs,
+		Country:               string(attrs.Country),
+		BaseCurrency:          string(attrs.BaseCurrency),
+		BankIDCode:            string(attrs.BankIDCode),
+		AccountClassification: string(attrs.AccountClassification),
+		Switched:              attrs.Switched,
+	}
+
+	b, err := json.Marshal(plain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode attributes. %w", err)
+	}
+
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode attributes. %w", err)
+	}
+	return m, nil
+}
+
+// isZero reports whether the given decoded JSON value v is a zero value. The booleans are never zero, so that they are
+// always compared.
+func isZero(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case float64:
+		return t == 0
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	default:
+		return false
+	}
+}
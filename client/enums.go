@@ -0,0 +1,165 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ResourceType represents the type of a resource of the Accounts API.
+type ResourceType string
+
+// AccountClassification represents the classification of an account.
+type AccountClassification string
+
+// BankIDCode represents the type of the bank ID of an account, which depends on its country.
+type BankIDCode string
+
+// Country represents an ISO 3166-1 alpha-2 country code.
+type Country string
+
+// Currency represents an ISO 4217 currency code.
+type Currency string
+
+const (
+	TypeAccounts ResourceType = "accounts"
+)
+
+const (
+	ClassificationPersonal AccountClassification = "Personal"
+	ClassificationBusiness AccountClassification = "Business"
+)
+
+const (
+	BankIDCodeAUBSB BankIDCode = "AUBSB"
+	BankIDCodeBE    BankIDCode = "BE"
+	BankIDCodeCACPA BankIDCode = "CACPA"
+	BankIDCodeCHBCC BankIDCode = "CHBCC"
+	BankIDCodeDEBLZ BankIDCode = "DEBLZ"
+	BankIDCodeESNCC BankIDCode = "ESNCC"
+	BankIDCodeFR    BankIDCode = "FR"
+	BankIDCodeGBDSC BankIDCode = "GBDSC"
+	BankIDCodeGRBIC BankIDCode = "GRBIC"
+	BankIDCodeHKNCC BankIDCode = "HKNCC"
+	BankIDCodeITNCC BankIDCode = "ITNCC"
+	BankIDCodeLULUX BankIDCode = "LULUX"
+	BankIDCodePLKNR BankIDCode = "PLKNR"
+	BankIDCodePTNCC BankIDCode = "PTNCC"
+	BankIDCodeUSABA BankIDCode = "USABA"
+)
+
+// The countries supported by Form3. Any other ISO 3166-1 alpha-2 country code is valid too.
+const (
+	CountryAU Country = "AU"
+	CountryBE Country = "BE"
+	CountryCA Country = "CA"
+	CountryCH Country = "CH"
+	CountryDE Country = "DE"
+	CountryES Country = "ES"
+	CountryFR Country = "FR"
+	CountryGB Country = "GB"
+	CountryGR Country = "GR"
+	CountryHK Country = "HK"
+	CountryIT Country = "IT"
+	CountryLU Country = "LU"
+	CountryNL Country = "NL"
+	CountryPL Country = "PL"
+	CountryPT Country = "PT"
+	CountryUS Country = "US"
+)
+
+// The currencies of the countries supported by Form3. Any other ISO 4217 currency code is valid too.
+const (
+	CurrencyAUD Currency = "AUD"
+	CurrencyCAD Currency = "CAD"
+	CurrencyCHF Currency = "CHF"
+	CurrencyEUR Currency = "EUR"
+	CurrencyGBP Currency = "GBP"
+	CurrencyHKD Currency = "HKD"
+	CurrencyPLN Currency = "PLN"
+	CurrencyUSD Currency = "USD"
+)
+
+// IsValid reports whether t is a known resource type.
+func (t ResourceType) IsValid() bool {
+	return t == TypeAccounts
+}
+
+// IsValid reports whether c is a known account classification.
+func (c AccountClassification) IsValid() bool {
+	return c == ClassificationPersonal || c == ClassificationBusiness
+}
+
+// IsValid reports whether c is a known bank ID code.
+func (c BankIDCode) IsValid() bool {
+	switch c {
+	case BankIDCodeAUBSB, BankIDCodeBE, BankIDCodeCACPA, BankIDCodeCHBCC, BankIDCodeDEBLZ, BankIDCodeESNCC,
+		BankIDCodeFR, BankIDCodeGBDSC, BankIDCodeGRBIC, BankIDCodeHKNCC, BankIDCodeITNCC, BankIDCodeLULUX,
+		BankIDCodePLKNR, BankIDCodePTNCC, BankIDCodeUSABA:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValid reports whether c is an ISO 3166-1 alpha-2 country code.
+func (c Country) IsValid() bool {
+	return isCountry(string(c))
+}
+
+// IsValid reports whether c is an ISO 4217 currency code.
+func (c Currency) IsValid() bool {
+	return isCurrency(string(c))
+}
+
+// The enums are encoded strictly: an unknown value is rejected with ErrUnknownValue, so that it is never sent to the
+// Accounts API. An empty value stands for an unset field, hence it is always accepted.
+// They are decoded leniently: any value is accepted, so that the values added by the service do not break the Client.
+
+func (t ResourceType) MarshalJSON() ([]byte, error) {
+	return marshalEnum("resource type", string(t), t == "" || t.IsValid())
+}
+
+func (t *ResourceType) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, (*string)(t))
+}
+
+func (c AccountClassification) MarshalJSON() ([]byte, error) {
+	return marshalEnum("account classification", string(c), c == "" || c.IsValid())
+}
+
+func (c *AccountClassification) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, (*string)(c))
+}
+
+func (c BankIDCode) MarshalJSON() ([]byte, error) {
+	return marshalEnum("bank ID code", string(c), c == "" || c.IsValid())
+}
+
+func (c *BankIDCode) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, (*string)(c))
+}
+
+func (c Country) MarshalJSON() ([]byte, error) {
+	return marshalEnum("country", string(c), c == "" || c.IsValid())
+}
+
+func (c *Country) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, (*string)(c))
+}
+
+func (c Currency) MarshalJSON() ([]byte, error) {
+	return marshalEnum("currency", string(c), c == "" || c.IsValid())
+}
+
+func (c *Currency) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, (*string)(c))
+}
+
+// marshalEnum returns the JSON encoding of the given value v of an enum named kind, or ErrUnknownValue if it is not
+// valid.
+func marshalEnum(kind, v string, valid bool) ([]byte, error) {
+	if !valid {
+		return nil, fmt.Errorf("%w: %s %q", ErrUnknownValue, kind, v)
+	}
+	return json.Marshal(v)
+}
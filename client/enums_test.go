@@ -0,0 +1,70 @@
+// +build unit
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEnumsMarshal(t *testing.T) {
+	type testData struct {
+		v    interface{}
+		want string
+		err  error
+	}
+
+	var golds = []testData{
+		0:  {ClassificationPersonal, `"Personal"`, nil},
+		1:  {AccountClassification("personal"), "", ErrUnknownValue},
+		2:  {BankIDCodeGBDSC, `"GBDSC"`, nil},
+		3:  {BankIDCode("GBDS"), "", ErrUnknownValue},
+		4:  {CountryGB, `"GB"`, nil},
+		5:  {Country("IE"), `"IE"`, nil},
+		6:  {Country("UK"), "", ErrUnknownValue},
+		7:  {CurrencyGBP, `"GBP"`, nil},
+		8:  {Currency("gbp"), "", ErrUnknownValue},
+		9:  {TypeAccounts, `"accounts"`, nil},
+		10: {ResourceType("account"), "", ErrUnknownValue},
+		11: {Country(""), `""`, nil},
+	}
+
+	for i, g := range golds {
+		got, err := json.Marshal(g.v)
+
+		assert.Equal(t, g.want, string(got), fmt.Sprintf("%d. Want JSON %s, but got %s", i, g.want, got))
+		assert.True(t, errors.Is(err, g.err), fmt.Sprintf("%d. Want error %+v, but got %+v", i, g.err, err))
+	}
+}
+
+func TestEnumsUnmarshal(t *testing.T) {
+	// Unknown values added by the service are decoded as they are
+	body := `{"id": "1", "type": "account_v2", "attributes": {"country": "ZZ", "base_currency": "XXX",
+		"bank_id_code": "NEWCODE", "account_classification": "Charity"}}`
+
+	var a Account
+	err := json.Unmarshal([]byte(body), &a)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ResourceType("account_v2"), a.Type)
+	assert.Equal(t, Country("ZZ"), a.Attributes.Country)
+	assert.Equal(t, Currency("XXX"), a.Attributes.BaseCurrency)
+	assert.Equal(t, BankIDCode("NEWCODE"), a.Attributes.BankIDCode)
+	assert.Equal(t, AccountClassification("Charity"), a.Attributes.AccountClassification)
+	assert.False(t, a.Attributes.AccountClassification.IsValid())
+}
+
+func TestCreateUnknownValue(t *testing.T) {
+	c, err := New("http://localhost:8080")
+	assert.NoError(t, err)
+
+	a := accountOne
+	a.Attributes.AccountClassification = "personal"
+
+	_, err = c.Create(&AccountResource{Data: a})
+
+	assert.True(t, errors.Is(err, ErrUnknownValue), fmt.Sprintf("Want error %+v, but got %+v", ErrUnknownValue, err))
+}
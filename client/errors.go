@@ -0,0 +1,180 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError represents an unsuccessful response returned by the Accounts API.
+// It wraps one of the sentinel errors (e.g. ErrBadInput), so it can be matched using errors.Is().
+type APIError struct {
+	StatusCode   int
+	ErrorCode    string
+	ErrorMessage string
+	Method       string
+	URL          string
+	Body         []byte
+
+	// RetryAfter is the wait requested by the service in the Retry-After header, if any (e.g. when rate limited).
+	RetryAfter time.Duration
+
+	err error
+}
+
+// errorDetail represents an error in the response body returned by the service.
+type errorDetail struct {
+	ErrorCode string `json:"error_code"`
+	ErrorMsg  string `json:"error_message"`
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.err)
+	if e.ErrorCode != "" {
+		msg = fmt.Sprintf("%s [%s]", msg, e.ErrorCode)
+	}
+	if e.ErrorMessage != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.ErrorMessage)
+	}
+	return msg
+}
+
+// Unwrap returns the sentinel error which classifies this APIError.
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the given target is ErrBadInput for an ErrUnauthorized error, so that the 401 responses still
+// match ErrBadInput as they did before ErrUnauthorized was introduced.
+func (e *APIError) Is(target error) bool {
+	return target == ErrBadInput && e.err == ErrUnauthorized
+}
+
+// ConflictError represents an account which already exists, but differs from the one being created.
+// It matches both ErrConflictingAccount and ErrConflict using errors.Is().
+type ConflictError struct {
+	// Existing is the account which already exists.
+	Existing *AccountResource
+
+	// Diff holds the differing fields, sorted by name.
+	Diff []FieldDiff
+}
+
+func (e *ConflictError) Error() string {
+	fields := make([]string, len(e.Diff))
+	for i, d := range e.Diff {
+		fields[i] = fmt.Sprintf("%s (want %v, got %v)", d.Field, d.Want, d.Got)
+	}
+	return fmt.Sprintf("%s %s: %s", ErrConflictingAccount, e.Existing.Data.ID, strings.Join(fields, ", "))
+}
+
+// Unwrap returns ErrConflictingAccount.
+func (e *ConflictError) Unwrap() error {
+	return ErrConflictingAccount
+}
+
+// Is reports whether the given target is ErrConflict, so that ConflictError can be handled as any other conflict.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// newAPIError returns an APIError classified as err for the given response resp.
+// The error detail is read from the response body, if possible.
+func newAPIError(resp *http.Response, err error) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		err:        err,
+	}
+
+	if d, ok := retryAfter(resp); ok {
+		apiErr.RetryAfter = d
+	}
+
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.URL = resp.Request.URL.String()
+	}
+
+	if resp.Body != nil {
+		body, readErr := ioutil.ReadAll(resp.Body)
+		if readErr == nil {
+			apiErr.Body = body
+		}
+	}
+
+	var errDetail errorDetail
+	if len(apiErr.Body) > 0 && json.Unmarshal(apiErr.Body, &errDetail) == nil {
+		apiErr.ErrorCode = errDetail.ErrorCode
+		apiErr.ErrorMessage = errDetail.ErrorMsg
+	}
+
+	return apiErr
+}
+
+// unexpectedStatus returns an APIError classified according to the status code of the given response resp.
+// Use it for the status codes not explicitly handled by each operation.
+func unexpectedStatus(resp *http.Response) *APIError {
+	var apiErr *APIError
+	if resp.StatusCode == http.StatusTooManyRequests {
+		apiErr = newAPIError(resp, ErrRateLimited)
+	} else if resp.StatusCode == http.StatusUnauthorized {
+		apiErr = newAPIError(resp, ErrUnauthorized)
+	} else if resp.StatusCode >= http.StatusInternalServerError {
+		apiErr = newAPIError(resp, ErrServerError)
+	} else if resp.StatusCode >= http.StatusBadRequest {
+		apiErr = newAPIError(resp, ErrBadInput)
+	} else {
+		// Unknown error (not according the specifications)
+		apiErr = newAPIError(resp, ErrUnknown)
+	}
+
+	return apiErr
+}
+
+// errorCodeOf returns the error code in the body of the given unsuccessful response resp, if any. The body is left
+// available to be read again.
+func errorCodeOf(resp *http.Response) string {
+	if resp.StatusCode < http.StatusBadRequest || resp.Body == nil {
+		return ""
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var errDetail errorDetail
+	if json.Unmarshal(body, &errDetail) != nil {
+		return ""
+	}
+	return errDetail.ErrorCode
+}
+
+// retryAfter returns the wait requested in the Retry-After header of the given response resp, either in seconds or as
+// an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
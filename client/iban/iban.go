@@ -0,0 +1,171 @@
+// Package iban parses, validates and builds International Bank Account Numbers (IBAN) as per ISO 13616.
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Errors
+	ErrUnsupportedCountry = errors.New("unsupported country")
+	ErrInvalidLength      = errors.New("invalid length")
+	ErrInvalidFormat      = errors.New("invalid format")
+	ErrInvalidChecksum    = errors.New("invalid checksum")
+	ErrNotBuildable       = errors.New("not buildable")
+)
+
+// IBAN represents a parsed IBAN, split into its components.
+type IBAN struct {
+	Country     string
+	CheckDigits string
+	BBAN        string
+
+	// BankCode is the bank identifier which precedes the bank ID in some countries (e.g. the BIC bank code in GB).
+	BankCode      string
+	BankID        string
+	AccountNumber string
+}
+
+// String returns the IBAN in its electronic format, i.e. without spaces.
+func (i *IBAN) String() string {
+	return i.Country + i.CheckDigits + i.BBAN
+}
+
+// Normalize returns the given IBAN s in its electronic format: upper case and without spaces.
+func Normalize(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+// Format returns the given IBAN s in its print format: upper case and in groups of four characters.
+func Format(s string) string {
+	s = Normalize(s)
+
+	var groups []string
+	for len(s) > 4 {
+		groups = append(groups, s[:4])
+		s = s[4:]
+	}
+	return strings.Join(append(groups, s), " ")
+}
+
+// Validate checks the given IBAN s: its per-country length and structure, and its mod-97 checksum.
+func Validate(s string) error {
+	_, err := Parse(s)
+	return err
+}
+
+// Parse validates the given IBAN s and splits it into its components. Spaces are ignored.
+func Parse(s string) (*IBAN, error) {
+	s = Normalize(s)
+	if len(s) < 4 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidLength, s)
+	}
+
+	country := s[:2]
+	sp, ok := specs[country]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCountry, country)
+	}
+	if len(s) != 4+sp.length() {
+		return nil, fmt.Errorf("%w: %d characters for country %s, but %d expected", ErrInvalidLength, len(s), country,
+			4+sp.length())
+	}
+	if !isDigits(s[2:4]) || !sp.matches(s[4:]) {
+		return nil, fmt.Errorf("%w: %q for country %s", ErrInvalidFormat, s, country)
+	}
+	if mod97(s[4:]+s[:4]) != 1 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidChecksum, s)
+	}
+
+	bban := s[4:]
+	return &IBAN{
+		Country:       country,
+		CheckDigits:   s[2:4],
+		BBAN:          bban,
+		BankCode:      sp.bankCode.of(bban),
+		BankID:        sp.bankID.of(bban),
+		AccountNumber: sp.account.of(bban),
+	}, nil
+}
+
+// Build returns the IBAN of the given country, bankID and accountNumber, computing its check digits. The account
+// number is padded with leading zeros if it is shorter than required by the country; any other component whose width
+// differs from the one required by the country is rejected with ErrInvalidLength.
+// It returns ErrNotBuildable for the countries whose BBAN holds a bank code (use BuildWithBankCode() instead) or
+// national check digits.
+func Build(country, bankID, accountNumber string) (string, error) {
+	return BuildWithBankCode(country, "", bankID, accountNumber)
+}
+
+// BuildWithBankCode is like Build, but it also takes the given bankCode, required by some countries (e.g. GB).
+func BuildWithBankCode(country, bankCode, bankID, accountNumber string) (string, error) {
+	sp, ok := specs[country]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCountry, country)
+	}
+	if sp.bankCode.width()+sp.bankID.width()+sp.account.width() != sp.length() {
+		return "", fmt.Errorf("%w: national check digits required for country %s", ErrNotBuildable, country)
+	}
+	if sp.bankCode.width() > 0 && bankCode == "" {
+		return "", fmt.Errorf("%w: bank code required for country %s", ErrNotBuildable, country)
+	}
+
+	if w := sp.account.width(); len(accountNumber) < w {
+		accountNumber = strings.Repeat("0", w-len(accountNumber)) + accountNumber
+	}
+
+	components := []struct {
+		name, value string
+		width       int
+	}{
+		{"bank code", bankCode, sp.bankCode.width()},
+		{"bank ID", bankID, sp.bankID.width()},
+		{"account number", accountNumber, sp.account.width()},
+	}
+	for _, c := range components {
+		if len(c.value) != c.width {
+			return "", fmt.Errorf("%w: %d %s characters for country %s, but %d expected", ErrInvalidLength,
+				len(c.value), c.name, country, c.width)
+		}
+	}
+
+	bban := bankCode + bankID + accountNumber
+	if !sp.matches(bban) {
+		return "", fmt.Errorf("%w: BBAN %q for country %s", ErrInvalidFormat, bban, country)
+	}
+
+	check := 98 - mod97(bban+country+"00")
+	return fmt.Sprintf("%s%02d%s", country, check, bban), nil
+}
+
+// mod97 returns the remainder of the division by 97 of the given alphanumeric string s, once converted to a number
+// replacing every letter with two digits (A = 10, ..., Z = 35).
+func mod97(s string) int {
+	var digits strings.Builder
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	if !ok {
+		return -1
+	}
+	return int(new(big.Int).Mod(n, big.NewInt(97)).Int64())
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+// +build unit
+
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	type testData struct {
+		iban string
+		want *IBAN
+		err  error
+	}
+
+	var golds = []testData{
+		0: {"GB16NWBK40030041426819", &IBAN{
+			Country:       "GB",
+			CheckDigits:   "16",
+			BBAN:          "NWBK40030041426819",
+			BankCode:      "NWBK",
+			BankID:        "400300",
+			AccountNumber: "41426819",
+		}, nil},
+		1: {"de89 3704 0044 0532 0130 00", &IBAN{
+			Country:       "DE",
+			CheckDigits:   "89",
+			BBAN:          "370400440532013000",
+			BankID:        "37040044",
+			AccountNumber: "0532013000",
+		}, nil},
+		2: {"FR1420041010050500013M02606", &IBAN{
+			Country:       "FR",
+			CheckDigits:   "14",
+			BBAN:          "20041010050500013M02606",
+			BankID:        "2004101005",
+			AccountNumber: "0500013M026",
+		}, nil},
+		3: {"GB11NWBK40030041426819", nil, ErrInvalidChecksum},
+		4: {"GB16NWBK4003004142681", nil, ErrInvalidLength},
+		5: {"GB16NWBK4003004142681X", nil, ErrInvalidFormat},
+		6: {"XX16NWBK40030041426819", nil, ErrUnsupportedCountry},
+		7: {"GB", nil, ErrInvalidLength},
+	}
+
+	for i, g := range golds {
+		got, err := Parse(g.iban)
+
+		assert.Equal(t, g.want, got, fmt.Sprintf("%d. Want IBAN %+v, but got %+v", i, g.want, got))
+		assert.True(t, errors.Is(err, g.err), fmt.Sprintf("%d. Want error %+v, but got %+v", i, g.err, err))
+	}
+}
+
+func TestBuild(t *testing.T) {
+	type testData struct {
+		country       string
+		bankCode      string
+		bankID        string
+		accountNumber string
+		want          string
+		err           error
+	}
+
+	var golds = []testData{
+		0: {"GB", "NWBK", "400300", "41426819", "GB16NWBK40030041426819", nil},
+		1: {"DE", "", "37040044", "532013000", "DE89370400440532013000", nil},
+		2: {"GB", "", "400300", "41426819", "", ErrNotBuildable},
+		3: {"FR", "", "2004101005", "0500013M026", "", ErrNotBuildable},
+		4: {"DE", "", "3704004", "532013000", "", ErrInvalidLength},
+		5: {"DE", "", "3704004X", "532013000", "", ErrInvalidFormat},
+		6: {"XX", "", "37040044", "532013000", "", ErrUnsupportedCountry},
+		// The bank ID does not fit its span, even though the BBAN length does
+		7: {"DE", "", "1234567", "12345678901", "", ErrInvalidLength},
+		8: {"DE", "", "37040044", "12345678901", "", ErrInvalidLength},
+		9: {"GB", "NWB", "4003000", "41426819", "", ErrInvalidLength},
+	}
+
+	for i, g := range golds {
+		got, err := BuildWithBankCode(g.country, g.bankCode, g.bankID, g.accountNumber)
+
+		assert.Equal(t, g.want, got, fmt.Sprintf("%d. Want IBAN %s, but got %s", i, g.want, got))
+		assert.True(t, errors.Is(err, g.err), fmt.Sprintf("%d. Want error %+v, but got %+v", i, g.err, err))
+		if err == nil {
+			assert.NoError(t, Validate(got))
+		}
+	}
+
+	got, err := Build("DE", "37040044", "0532013000")
+	assert.NoError(t, err)
+	assert.Equal(t, "DE89370400440532013000", got)
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "GB16 NWBK 4003 0041 4268 19", Format("gb16nwbk40030041426819"))
+	assert.Equal(t, "DE89370400440532013000", Normalize(" DE89 3704 0044 0532 0130 00 "))
+}
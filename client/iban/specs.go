@@ -0,0 +1,97 @@
+package iban
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// span represents the position of a component within the BBAN, from start (inclusive) to end (exclusive).
+type span struct {
+	start, end int
+}
+
+func (s span) width() int {
+	return s.end - s.start
+}
+
+// of returns the component at this span of the given bban, or an empty string if the country has no such component.
+func (s span) of(bban string) string {
+	if s.width() == 0 {
+		return ""
+	}
+	return bban[s.start:s.end]
+}
+
+// spec represents the structure of the IBANs of a country, as per the SWIFT IBAN registry.
+type spec struct {
+	bankCode span
+	bankID   span
+	account  span
+
+	pattern *regexp.Regexp
+	len     int
+}
+
+// specs are the supported IBAN structures, keyed by ISO 3166-1 alpha-2 country code. The bank ID span matches the
+// bank_id attribute of the Form3 accounts in that country.
+var specs = map[string]*spec{
+	"AT": newSpec("5!n11!n", span{}, span{0, 5}, span{5, 16}),
+	"BE": newSpec("3!n7!n2!n", span{}, span{0, 3}, span{3, 10}),
+	"CH": newSpec("5!n12!c", span{}, span{0, 5}, span{5, 17}),
+	"DE": newSpec("8!n10!n", span{}, span{0, 8}, span{8, 18}),
+	"DK": newSpec("4!n9!n1!n", span{}, span{0, 4}, span{4, 14}),
+	"ES": newSpec("4!n4!n1!n1!n10!n", span{}, span{0, 8}, span{10, 20}),
+	"FI": newSpec("3!n11!n", span{}, span{0, 3}, span{3, 14}),
+	"FR": newSpec("5!n5!n11!c2!n", span{}, span{0, 10}, span{10, 21}),
+	"GB": newSpec("4!a6!n8!n", span{0, 4}, span{4, 10}, span{10, 18}),
+	"GR": newSpec("3!n4!n16!c", span{}, span{0, 7}, span{7, 23}),
+	"IE": newSpec("4!a6!n8!n", span{0, 4}, span{4, 10}, span{10, 18}),
+	"IT": newSpec("1!a5!n5!n12!c", span{}, span{1, 11}, span{11, 23}),
+	"LI": newSpec("5!n12!c", span{}, span{0, 5}, span{5, 17}),
+	"LU": newSpec("3!n13!c", span{}, span{0, 3}, span{3, 16}),
+	"MC": newSpec("5!n5!n11!c2!n", span{}, span{0, 10}, span{10, 21}),
+	"NL": newSpec("4!a10!n", span{0, 4}, span{}, span{4, 14}),
+	"NO": newSpec("4!n6!n1!n", span{}, span{0, 4}, span{4, 11}),
+	"PL": newSpec("8!n16!n", span{}, span{0, 8}, span{8, 24}),
+	"PT": newSpec("4!n4!n11!n2!n", span{}, span{0, 8}, span{8, 19}),
+	"SE": newSpec("3!n16!n1!n", span{}, span{0, 3}, span{3, 20}),
+}
+
+// newSpec returns the spec of the given BBAN format and component spans. The format is given in the registry notation,
+// e.g. "4!a6!n8!n": 'n' digits, 'a' upper case letters and 'c' alphanumeric characters, each preceded by its fixed
+// length. It panics if the format is malformed.
+func newSpec(format string, bankCode, bankID, account span) *spec {
+	var expr string
+	length := 0
+	for _, m := range formatPattern.FindAllStringSubmatch(format, -1) {
+		n, _ := strconv.Atoi(m[1])
+		length += n
+		expr += charClasses[m[2]] + "{" + m[1] + "}"
+	}
+	if formatPattern.ReplaceAllString(format, "") != "" {
+		panic("iban: malformed BBAN format " + format)
+	}
+
+	return &spec{
+		bankCode: bankCode,
+		bankID:   bankID,
+		account:  account,
+		pattern:  regexp.MustCompile("^" + expr + "$"),
+		len:      length,
+	}
+}
+
+var (
+	formatPattern = regexp.MustCompile(`([0-9]+)!([nac])`)
+	charClasses   = map[string]string{"n": "[0-9]", "a": "[A-Z]", "c": "[0-9A-Z]"}
+)
+
+// length returns the length of the BBAN.
+func (s *spec) length() int {
+	return s.len
+}
+
+// matches reports whether the given bban has the structure of this spec.
+func (s *spec) matches(bban string) bool {
+	return s.pattern.MatchString(bban)
+}
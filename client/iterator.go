@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"strconv"
+)
+
+// defaultPageSize is the page size used by ListAll() when none is given.
+const defaultPageSize = 100
+
+// AccountIterator walks through all the accounts listed by the Accounts API, fetching one page at a time.
+// Use it as follows:
+//
+//	it := c.ListAll(ctx, 100)
+//	for it.Next() {
+//		a := it.Account()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type AccountIterator struct {
+	ctx      context.Context
+	client   *Client
+	pageSize int64
+	pageNum  int64
+	filter   *AccountFilter
+	last     *AccountsResource
+	page     []Account
+	pos      int
+	current  Account
+	done     bool
+	err      error
+}
+
+// ListAll returns an AccountIterator over all the accounts, listed in pages of the given pageSize. Only one page is held
+// in memory at a time.
+func (c *Client) ListAll(ctx context.Context, pageSize int64) *AccountIterator {
+	return c.ListAllFiltered(ctx, pageSize, nil)
+}
+
+// ListAllFiltered is like ListAll, but it only walks through the accounts matching the given filter.
+func (c *Client) ListAllFiltered(ctx context.Context, pageSize int64, filter *AccountFilter) *AccountIterator {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	return &AccountIterator{
+		ctx:      ctx,
+		client:   c,
+		pageSize: pageSize,
+		filter:   filter,
+	}
+}
+
+// Next advances the iterator to the next account, fetching the next page if needed. It returns false once all the
+// accounts have been walked through or an error occurred, which is available in Err().
+func (it *AccountIterator) Next() bool {
+	for it.pos >= len(it.page) {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+
+	it.current = it.page[it.pos]
+	it.pos++
+	return true
+}
+
+// Account returns the current account. Call it after Next() returned true.
+func (it *AccountIterator) Account() Account {
+	return it.current
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *AccountIterator) Err() error {
+	return it.err
+}
+
+// fetch fetches the next page. It follows the next link advertised by the service, if any. Otherwise, it asks for the
+// next page number, until an empty or short page is found.
+func (it *AccountIterator) fetch() {
+	var accounts *AccountsResource
+	var err error
+	if it.last != nil && it.last.Links != nil {
+		accounts, err = it.last.NextPage(it.ctx)
+	} else {
+		accounts, err = it.client.ListWithOpts(it.ctx, &ListOpts{
+			PageOpts: PageOpts{
+				Number: PageNumOptOf(strconv.FormatInt(it.pageNum, 10)),
+				Size:   PageSizeOptOf(it.pageSize),
+			},
+			Filter: it.filter,
+		})
+	}
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.last = accounts
+	it.page = accounts.Data
+	it.pos = 0
+	it.pageNum++
+	if accounts.Links != nil {
+		it.done = !accounts.HasNext() || len(it.page) == 0
+	} else {
+		it.done = int64(len(it.page)) < it.pageSize
+	}
+}
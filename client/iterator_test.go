@@ -0,0 +1,155 @@
+// +build unit
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestListAll(t *testing.T) {
+	type testData struct {
+		existing []Account
+		pageSize int64
+		// failPage is the page number which fails on the server side, if non-negative
+		failPage int64
+		want     []Account
+		requests int
+		err      error
+	}
+
+	var golds = []testData{
+		0: {[]Account{}, 2, -1, nil, 1, nil},
+		1: {[]Account{accountOne, accountTwo, accountThree}, 2, -1, []Account{accountOne, accountTwo, accountThree}, 2, nil},
+		2: {[]Account{accountOne, accountTwo}, 2, -1, []Account{accountOne, accountTwo}, 2, nil},
+		3: {[]Account{accountOne, accountTwo, accountThree}, 1, -1, []Account{accountOne, accountTwo, accountThree}, 4, nil},
+		4: {[]Account{accountOne, accountTwo, accountThree}, 0, -1, []Account{accountOne, accountTwo, accountThree}, 1, nil},
+		5: {[]Account{accountOne, accountTwo, accountThree}, 2, 1, []Account{accountOne, accountTwo}, 2, ErrServerError},
+	}
+
+	var testListAll = func(t *testing.T, tc int, data testData) {
+		requests := 0
+
+		// Setup mocked server serving the existing accounts in pages
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			requests++
+
+			pNum, err := strconv.ParseInt(req.URL.Query().Get("page[number]"), 10, 64)
+			assert.NoError(t, err)
+			pSize, err := strconv.ParseInt(req.URL.Query().Get("page[size]"), 10, 64)
+			assert.NoError(t, err)
+
+			if pNum == data.failPage {
+				serveError(t, rw, http.StatusInternalServerError)
+				return
+			}
+
+			start, end := pNum*pSize, (pNum+1)*pSize
+			total := int64(len(data.existing))
+			if start > total {
+				start = total
+			}
+			if end > total {
+				end = total
+			}
+			serveContent(t, rw, http.StatusOK, AccountsResource{Data: data.existing[start:end]})
+		}))
+		defer server.Close()
+
+		// Setup client
+		client := setupClient(t, server.URL)
+
+		var got []Account
+		it := client.ListAll(context.Background(), data.pageSize)
+		for it.Next() {
+			got = append(got, it.Account())
+		}
+
+		assert.Equal(t, data.want, got, fmt.Sprintf("%d. Want accounts %+v, but got %+v", tc, data.want, got))
+		assert.True(t, errors.Is(it.Err(), data.err), fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, it.Err()))
+		assert.Equal(t, data.requests, requests, fmt.Sprintf("%d. Want %d requests, but got %d", tc, data.requests, requests))
+		assert.False(t, it.Next(), fmt.Sprintf("%d. The iterator must be exhausted", tc))
+	}
+
+	for i, g := range golds {
+		testListAll(t, i, g)
+	}
+}
+
+func TestListAllFollowsLinks(t *testing.T) {
+	var requested []string
+
+	// Setup mocked server which advertises the next page using opaque cursors
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requested = append(requested, req.URL.RawQuery)
+
+		switch req.URL.Query().Get("page[cursor]") {
+		case "":
+			serveContent(t, rw, http.StatusOK, AccountsResource{
+				Data:  []Account{accountOne},
+				Links: &Links{Next: "/v1/organisation/accounts?page[cursor]=abc"},
+			})
+		case "abc":
+			serveContent(t, rw, http.StatusOK, AccountsResource{
+				Data:  []Account{accountTwo, accountThree},
+				Links: &Links{Next: "/v1/organisation/accounts?page[cursor]=def"},
+			})
+		default:
+			serveContent(t, rw, http.StatusOK, AccountsResource{
+				Data:  []Account{},
+				Links: &Links{Next: "/v1/organisation/accounts?page[cursor]=ghi"},
+			})
+		}
+	}))
+	defer server.Close()
+
+	// Setup client
+	client := setupClient(t, server.URL)
+
+	var got []Account
+	it := client.ListAll(context.Background(), 10)
+	for it.Next() {
+		got = append(got, it.Account())
+	}
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []Account{accountOne, accountTwo, accountThree}, got)
+	assert.Equal(t, []string{"page%5Bnumber%5D=0&page%5Bsize%5D=10", "page[cursor]=abc", "page[cursor]=def"}, requested)
+}
+
+func TestListAllFiltered(t *testing.T) {
+	requests := 0
+
+	// Setup mocked server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		assert.Equal(t, "GB", req.URL.Query().Get("filter[country]"))
+		assert.Equal(t, "400300", req.URL.Query().Get("filter[bank_id]"))
+
+		if req.URL.Query().Get("page[number]") == "0" {
+			serveContent(t, rw, http.StatusOK, AccountsResource{Data: []Account{accountOne, accountTwo}})
+		} else {
+			serveContent(t, rw, http.StatusOK, AccountsResource{Data: []Account{accountThree}})
+		}
+	}))
+	defer server.Close()
+
+	// Setup client
+	client := setupClient(t, server.URL)
+
+	var got []Account
+	it := client.ListAllFiltered(context.Background(), 2, &AccountFilter{Country: "GB", BankID: "400300"})
+	for it.Next() {
+		got = append(got, it.Account())
+	}
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []Account{accountOne, accountTwo, accountThree}, got)
+	assert.Equal(t, 2, requests)
+}
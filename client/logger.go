@@ -0,0 +1,118 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Logger represents a structured logger, which takes a message along with alternating keys and values.
+// Its method set is a subset of the one of *slog.Logger, so that it can be given to WithLogger() as it is.
+// Use NewStdLogger() to adapt a standard library *log.Logger.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// nopLogger is the Logger used unless told otherwise with WithLogger(). It discards everything.
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+// stdLogger adapts a *log.Logger to the Logger interface, writing the keys and values as key=value pairs.
+type stdLogger struct {
+	l *log.Logger
+}
+
+// NewStdLogger returns a Logger which writes to the given standard library logger l.
+func NewStdLogger(l *log.Logger) Logger {
+	return &stdLogger{l: l}
+}
+
+func (s *stdLogger) Debug(msg string, args ...interface{}) { s.log("DEBUG", msg, args) }
+func (s *stdLogger) Info(msg string, args ...interface{})  { s.log("INFO", msg, args) }
+func (s *stdLogger) Warn(msg string, args ...interface{})  { s.log("WARN", msg, args) }
+func (s *stdLogger) Error(msg string, args ...interface{}) { s.log("ERROR", msg, args) }
+
+func (s *stdLogger) log(level, msg string, args []interface{}) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "level=%s msg=%q", level, msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+		} else {
+			fmt.Fprintf(&b, " !BADKEY=%v", args[i])
+		}
+	}
+	s.l.Print(b.String())
+}
+
+// WithLogger makes the Client log every attempt to the given logger l: successful attempts at debug level, client
+// errors at info level, and server or transport errors at warn level when retried, or error level otherwise.
+// Only the method, path, status, latency, attempt number and error code are logged, never the query string, the bodies
+// or the error messages, which may hold personal data.
+func WithLogger(l Logger) Option {
+	return func(c *Client) error {
+		if l == nil {
+			return fmt.Errorf("%w: nil logger", ErrInvalidOption)
+		}
+		c.logger = l
+		return nil
+	}
+}
+
+// log returns the Logger of the Client.
+func (c *Client) log() Logger {
+	if c.logger == nil {
+		return nopLogger{}
+	}
+	return c.logger
+}
+
+// logAttempt logs the given attempt of the operation op, sending the request req, which ended with either the response
+// resp or the error err after the given latency. The attempt is retried when the given retrying is true.
+func (c *Client) logAttempt(op *operation, req *http.Request, resp *http.Response, err error, latency time.Duration,
+	retrying bool) {
+	args := []interface{}{
+		"operation", op.name,
+		"method", req.Method,
+		"path", req.URL.Path,
+		"attempt", op.attempts,
+		"latency", latency,
+	}
+
+	if err != nil {
+		// The URL held by url.Error may include the query string, which is left out
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		args = append(args, "error", err)
+	} else {
+		args = append(args, "status", resp.StatusCode)
+		if code := errorCodeOf(resp); code != "" {
+			args = append(args, "error_code", code)
+		}
+	}
+
+	const msg = "accounts api request"
+	switch {
+	case err == nil && resp.StatusCode < http.StatusBadRequest:
+		c.log().Debug(msg, args...)
+	case err == nil && resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests:
+		c.log().Info(msg, args...)
+	case retrying:
+		c.log().Warn(msg, args...)
+	default:
+		c.log().Error(msg, args...)
+	}
+}
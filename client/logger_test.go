@@ -0,0 +1,135 @@
+// +build unit
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// logEntry is a log entry recorded by recordingLogger.
+type logEntry struct {
+	level string
+	msg   string
+	args  map[string]interface{}
+}
+
+// recordingLogger is a Logger which records the log entries.
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) { l.record("DEBUG", msg, args) }
+func (l *recordingLogger) Info(msg string, args ...interface{})  { l.record("INFO", msg, args) }
+func (l *recordingLogger) Warn(msg string, args ...interface{})  { l.record("WARN", msg, args) }
+func (l *recordingLogger) Error(msg string, args ...interface{}) { l.record("ERROR", msg, args) }
+
+func (l *recordingLogger) record(level, msg string, args []interface{}) {
+	e := logEntry{level: level, msg: msg, args: make(map[string]interface{})}
+	for i := 0; i+1 < len(args); i += 2 {
+		e.args[args[i].(string)] = args[i+1]
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+}
+
+func TestLogger(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusNotFound}
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		status := statuses[calls]
+		calls++
+		if status == http.StatusOK {
+			serveContent(t, rw, status, AccountResource{Data: accountOne})
+		} else {
+			serveError(t, rw, status)
+		}
+	}))
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	c, err := New(server.URL,
+		WithLogger(logger),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, RetryStatuses: []int{http.StatusServiceUnavailable}}))
+	assert.NoError(t, err)
+
+	_, err = c.Fetch(accountOne.ID)
+	assert.NoError(t, err)
+
+	_, err = c.ListWithOpts(context.Background(), &ListOpts{Filter: &AccountFilter{IBAN: accountOne.Attributes.IBAN}})
+	assert.True(t, errors.Is(err, ErrBadInput))
+
+	if !assert.Len(t, logger.entries, 3) {
+		return
+	}
+
+	retried := logger.entries[0]
+	assert.Equal(t, "WARN", retried.level)
+	assert.Equal(t, "fetch", retried.args["operation"])
+	assert.Equal(t, http.MethodGet, retried.args["method"])
+	assert.Equal(t, "/v1/organisation/accounts/"+accountOne.ID, retried.args["path"])
+	assert.Equal(t, http.StatusServiceUnavailable, retried.args["status"])
+	assert.Equal(t, 1, retried.args["attempt"])
+	assert.Equal(t, "503", retried.args["error_code"])
+	assert.IsType(t, time.Duration(0), retried.args["latency"])
+
+	succeeded := logger.entries[1]
+	assert.Equal(t, "DEBUG", succeeded.level)
+	assert.Equal(t, 2, succeeded.args["attempt"])
+	assert.Equal(t, http.StatusOK, succeeded.args["status"])
+	assert.NotContains(t, succeeded.args, "error_code")
+
+	// The query string, which may hold personal data, is never logged
+	listed := logger.entries[2]
+	assert.Equal(t, "INFO", listed.level)
+	assert.Equal(t, "/v1/organisation/accounts", listed.args["path"])
+	for _, v := range listed.args {
+		assert.NotContains(t, fmt.Sprint(v), accountOne.Attributes.IBAN)
+	}
+}
+
+func TestLoggerTransportError(t *testing.T) {
+	logger := &recordingLogger{}
+	c, err := New("http://127.0.0.1:1",
+		WithLogger(logger),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	assert.NoError(t, err)
+
+	_, err = c.ListWithOpts(context.Background(), &ListOpts{Filter: &AccountFilter{IBAN: accountOne.Attributes.IBAN}})
+	assert.Error(t, err)
+
+	if assert.Len(t, logger.entries, 1) {
+		e := logger.entries[0]
+		assert.Equal(t, "ERROR", e.level)
+		assert.NotContains(t, fmt.Sprint(e.args["error"]), accountOne.Attributes.IBAN)
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewStdLogger(log.New(&buf, "", 0))
+
+	l.Warn("accounts api request", "method", "GET", "status", 503, "dangling")
+
+	assert.Equal(t, `level=WARN msg="accounts api request" method=GET status=503 !BADKEY=dangling`,
+		strings.TrimSpace(buf.String()))
+}
+
+func TestWithLogger(t *testing.T) {
+	_, err := New("http://localhost:8080", WithLogger(nil))
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MetricsRecorder records the metrics of the calls of the Client, labelled by operation: "create", "fetch", "list",
+// "update" or "delete". A call spans all its attempts. Its implementations must be safe for concurrent use.
+type MetricsRecorder interface {
+	// InFlight adds the given delta, either 1 or -1, to the number of calls of the operation op being run.
+	InFlight(op string, delta int)
+
+	// ObserveCall records a finished call of the operation op, which ended with the given status class ("2xx", "4xx",
+	// "5xx", ...) or "error" if no response was received, after the given latency and number of retries.
+	ObserveCall(op, statusClass string, latency time.Duration, retries int)
+}
+
+// WithMetrics makes the Client record the metrics of its calls with the given recorder r.
+func WithMetrics(r MetricsRecorder) Option {
+	return func(c *Client) error {
+		if r == nil {
+			return fmt.Errorf("%w: nil metrics recorder", ErrInvalidOption)
+		}
+		c.metrics = r
+		return nil
+	}
+}
+
+// statusClassOf returns the status class of the outcome of a call, either the response resp or the error err.
+func statusClassOf(resp *http.Response, err error) string {
+	if err != nil || resp == nil {
+		return "error"
+	}
+	return strconv.Itoa(resp.StatusCode/100) + "xx"
+}
+
+// DefaultLatencyBuckets are the upper bounds in seconds of the latency histogram buckets of InMemoryMetrics, unless
+// told otherwise.
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// InMemoryMetrics is a MetricsRecorder which holds the metrics in memory. It can be read in the tests, and exported in
+// the Prometheus text exposition format with WritePrometheus(), or served over HTTP since it is an http.Handler.
+type InMemoryMetrics struct {
+	mu        sync.Mutex
+	buckets   []float64
+	calls     map[callKey]int
+	retries   map[string]int
+	inFlight  map[string]int
+	latencies map[string]*histogram
+}
+
+// callKey identifies the calls of an operation ending with a given status class.
+type callKey struct {
+	op          string
+	statusClass string
+}
+
+// histogram represents the cumulative latency histogram of an operation.
+type histogram struct {
+	counts []int // one per bucket, plus +Inf
+	sum    float64
+	count  int
+}
+
+// NewInMemoryMetrics returns an empty InMemoryMetrics, with the given latency buckets in seconds, or
+// DefaultLatencyBuckets if none is given.
+func NewInMemoryMetrics(buckets ...float64) *InMemoryMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &InMemoryMetrics{
+		buckets:   buckets,
+		calls:     make(map[callKey]int),
+		retries:   make(map[string]int),
+		inFlight:  make(map[string]int),
+		latencies: make(map[string]*histogram),
+	}
+}
+
+// InFlight implements MetricsRecorder.
+func (m *InMemoryMetrics) InFlight(op string, delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[op] += delta
+}
+
+// ObserveCall implements MetricsRecorder.
+func (m *InMemoryMetrics) ObserveCall(op, statusClass string, latency time.Duration, retries int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls[callKey{op, statusClass}]++
+	m.retries[op] += retries
+
+	h, ok := m.latencies[op]
+	if !ok {
+		h = &histogram{counts: make([]int, len(m.buckets)+1)}
+		m.latencies[op] = h
+	}
+	secs := latency.Seconds()
+	for i, le := range m.buckets {
+		if secs <= le {
+			h.counts[i]++
+		}
+	}
+	h.counts[len(m.buckets)]++
+	h.sum += secs
+	h.count++
+}
+
+// Calls returns the number of calls of the operation op which ended with the given status class.
+func (m *InMemoryMetrics) Calls(op, statusClass string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[callKey{op, statusClass}]
+}
+
+// Retries returns the number of retries of the calls of the operation op.
+func (m *InMemoryMetrics) Retries(op string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.retries[op]
+}
+
+// InFlightCalls returns the number of calls of the operation op being run.
+func (m *InMemoryMetrics) InFlightCalls(op string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.inFlight[op]
+}
+
+// WritePrometheus writes the metrics to the given writer w in the Prometheus text exposition format.
+func (m *InMemoryMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "# HELP accountapi_client_requests_total Calls to the Accounts API by operation and status class.")
+	fmt.Fprintln(bw, "# TYPE accountapi_client_requests_total counter")
+	keys := make([]callKey, 0, len(m.calls))
+	for k := range m.calls {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].op != keys[j].op {
+			return keys[i].op < keys[j].op
+		}
+		return keys[i].statusClass < keys[j].statusClass
+	})
+	for _, k := range keys {
+		fmt.Fprintf(bw, "accountapi_client_requests_total{operation=%q,status_class=%q} %d\n", k.op, k.statusClass,
+			m.calls[k])
+	}
+
+	fmt.Fprintln(bw, "# HELP accountapi_client_request_duration_seconds Latency of the calls to the Accounts API, including the retries.")
+	fmt.Fprintln(bw, "# TYPE accountapi_client_request_duration_seconds histogram")
+	for _, op := range sortedKeys(m.latencies) {
+		h := m.latencies[op]
+		for i, le := range m.buckets {
+			fmt.Fprintf(bw, "accountapi_client_request_duration_seconds_bucket{operation=%q,le=%q} %d\n", op,
+				strconv.FormatFloat(le, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(bw, "accountapi_client_request_duration_seconds_bucket{operation=%q,le=\"+Inf\"} %d\n", op,
+			h.counts[len(m.buckets)])
+		fmt.Fprintf(bw, "accountapi_client_request_duration_seconds_sum{operation=%q} %s\n", op,
+			strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(bw, "accountapi_client_request_duration_seconds_count{operation=%q} %d\n", op, h.count)
+	}
+
+	fmt.Fprintln(bw, "# HELP accountapi_client_retries_total Retries of the calls to the Accounts API by operation.")
+	fmt.Fprintln(bw, "# TYPE accountapi_client_retries_total counter")
+	for _, op := range sortedKeys(m.retries) {
+		fmt.Fprintf(bw, "accountapi_client_retries_total{operation=%q} %d\n", op, m.retries[op])
+	}
+
+	fmt.Fprintln(bw, "# HELP accountapi_client_requests_in_flight Calls to the Accounts API being run by operation.")
+	fmt.Fprintln(bw, "# TYPE accountapi_client_requests_in_flight gauge")
+	for _, op := range sortedKeys(m.inFlight) {
+		fmt.Fprintf(bw, "accountapi_client_requests_in_flight{operation=%q} %d\n", op, m.inFlight[op])
+	}
+
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (m *InMemoryMetrics) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
+	rw.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WritePrometheus(rw)
+}
+
+// sortedKeys returns the sorted keys of the given map m, which must be keyed by string.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch t := m.(type) {
+	case map[string]int:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]*histogram:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
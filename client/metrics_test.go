@@ -0,0 +1,106 @@
+// +build unit
+
+package client
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusNotFound}
+	calls := 0
+	var inFlight int
+
+	metrics := NewInMemoryMetrics()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		inFlight = metrics.InFlightCalls("fetch")
+		status := statuses[calls]
+		calls++
+		if status == http.StatusOK {
+			serveContent(t, rw, status, AccountResource{Data: accountOne})
+		} else {
+			serveError(t, rw, status)
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL,
+		WithMetrics(metrics),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, RetryStatuses: []int{http.StatusServiceUnavailable}}))
+	assert.NoError(t, err)
+
+	_, err = c.Fetch(accountOne.ID)
+	assert.NoError(t, err)
+	_, err = c.Fetch(accountOne.ID)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	assert.Equal(t, 1, inFlight)
+	assert.Equal(t, 0, metrics.InFlightCalls("fetch"))
+	assert.Equal(t, 1, metrics.Calls("fetch", "2xx"))
+	assert.Equal(t, 1, metrics.Calls("fetch", "4xx"))
+	assert.Equal(t, 0, metrics.Calls("fetch", "5xx"))
+	assert.Equal(t, 1, metrics.Retries("fetch"))
+
+	// Transport errors have no status class
+	c, err = New("http://127.0.0.1:1", WithMetrics(metrics), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	assert.NoError(t, err)
+	_ = c.Delete(accountOne.ID, 0)
+	assert.Equal(t, 1, metrics.Calls("delete", "error"))
+}
+
+func TestWritePrometheus(t *testing.T) {
+	m := NewInMemoryMetrics(0.1, 0.5)
+	m.InFlight("list", 1)
+	m.ObserveCall("fetch", "2xx", 50*time.Millisecond, 0)
+	m.ObserveCall("fetch", "2xx", 300*time.Millisecond, 2)
+	m.ObserveCall("create", "4xx", time.Second, 0)
+
+	want := `# HELP accountapi_client_requests_total Calls to the Accounts API by operation and status class.
+# TYPE accountapi_client_requests_total counter
+accountapi_client_requests_total{operation="create",status_class="4xx"} 1
+accountapi_client_requests_total{operation="fetch",status_class="2xx"} 2
+# HELP accountapi_client_request_duration_seconds Latency of the calls to the Accounts API, including the retries.
+# TYPE accountapi_client_request_duration_seconds histogram
+accountapi_client_request_duration_seconds_bucket{operation="create",le="0.1"} 0
+accountapi_client_request_duration_seconds_bucket{operation="create",le="0.5"} 0
+accountapi_client_request_duration_seconds_bucket{operation="create",le="+Inf"} 1
+accountapi_client_request_duration_seconds_sum{operation="create"} 1
+accountapi_client_request_duration_seconds_count{operation="create"} 1
+accountapi_client_request_duration_seconds_bucket{operation="fetch",le="0.1"} 1
+accountapi_client_request_duration_seconds_bucket{operation="fetch",le="0.5"} 2
+accountapi_client_request_duration_seconds_bucket{operation="fetch",le="+Inf"} 2
+accountapi_client_request_duration_seconds_sum{operation="fetch"} 0.35
+accountapi_client_request_duration_seconds_count{operation="fetch"} 2
+# HELP accountapi_client_retries_total Retries of the calls to the Accounts API by operation.
+# TYPE accountapi_client_retries_total counter
+accountapi_client_retries_total{operation="create"} 0
+accountapi_client_retries_total{operation="fetch"} 2
+# HELP accountapi_client_requests_in_flight Calls to the Accounts API being run by operation.
+# TYPE accountapi_client_requests_in_flight gauge
+accountapi_client_requests_in_flight{operation="list"} 1
+`
+
+	var buf bytes.Buffer
+	assert.NoError(t, m.WritePrometheus(&buf))
+	assert.Equal(t, want, buf.String())
+
+	// Served over HTTP as well
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+	assert.Equal(t, want, string(body))
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+}
+
+func TestWithMetrics(t *testing.T) {
+	_, err := New("http://localhost:8080", WithMetrics(nil))
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Doer sends an HTTP request and returns its response. *http.Client implements it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to use an ordinary function as a Doer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a Doer, the next one in the chain, to act around every request sent through it (e.g. to add
+// headers, sign the request, record metrics or inject failures).
+type Middleware func(next Doer) Doer
+
+// WithMiddleware wraps every request sent by the Client in the given middlewares mws. They run in the given order, the
+// first one being the outermost, and any middlewares given in previous calls run first. The middlewares wrap every
+// attempt, including the retries, and they see the requests once fully built, right before they are sent by the HTTP
+// client.
+func WithMiddleware(mws ...Middleware) Option {
+	return func(c *Client) error {
+		for _, mw := range mws {
+			if mw == nil {
+				return fmt.Errorf("%w: nil middleware", ErrInvalidOption)
+			}
+		}
+		c.middlewares = append(c.middlewares, mws...)
+		return nil
+	}
+}
+
+// doer returns the Doer which sends the requests of the Client: its HTTP client wrapped in its middlewares.
+func (c *Client) doer() Doer {
+	var d Doer = c.httpClient
+	if c.httpClient == nil {
+		d = http.DefaultClient
+	}
+
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		d = c.middlewares[i](d)
+	}
+	return d
+}
@@ -0,0 +1,73 @@
+// +build unit
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var got http.Header
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = req.Header
+		calls++
+		if calls == 1 {
+			serveError(t, rw, http.StatusServiceUnavailable)
+			return
+		}
+		serveContent(t, rw, http.StatusOK, AccountResource{Data: accountOne})
+	}))
+	defer server.Close()
+
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				req.Header.Add("X-Middleware", name)
+				return next.Do(req)
+			})
+		}
+	}
+
+	c, err := New(server.URL,
+		WithMiddleware(tag("outer")),
+		WithMiddleware(tag("inner")),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, RetryStatuses: []int{http.StatusServiceUnavailable}}))
+	assert.NoError(t, err)
+
+	_, err = c.Fetch(accountOne.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"outer", "inner", "outer", "inner"}, order, "Want every attempt wrapped in order")
+	assert.Equal(t, []string{"outer", "inner"}, got["X-Middleware"])
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	chaos := errors.New("chaos")
+
+	c, err := New("http://localhost:8080",
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithMiddleware(func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, chaos
+			})
+		}))
+	assert.NoError(t, err)
+
+	_, err = c.Fetch(accountOne.ID)
+
+	assert.True(t, errors.Is(err, chaos), fmt.Sprintf("Want error %+v, but got %+v", chaos, err))
+}
+
+func TestWithMiddleware(t *testing.T) {
+	_, err := New("http://localhost:8080", WithMiddleware(nil))
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultExpiryDelta is how long before their expiry the tokens are refreshed, unless told otherwise.
+const defaultExpiryDelta = 10 * time.Second
+
+// ClientCredentials represents the configuration of the OAuth2 client credentials grant. Use it in the function
+// WithClientCredentials().
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	// ExpiryDelta is how long before their expiry the tokens are refreshed. It defaults to 10 seconds when zero.
+	ExpiryDelta time.Duration
+
+	// HTTPClient is the HTTP client used to request the tokens. It defaults to http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+// token represents an OAuth2 access token.
+type token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// tokenSource gets the access tokens from the token endpoint, and caches them until shortly before their expiry.
+type tokenSource struct {
+	cc ClientCredentials
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// WithClientCredentials makes the Client authenticate every request with a bearer token, obtained from the token
+// endpoint with the OAuth2 client credentials grant. The token is cached until shortly before its expiry, and shared by
+// all the concurrent calls. When the Accounts API rejects a request with 401 Unauthorized, the request is sent once
+// again with a fresh token.
+func WithClientCredentials(cc ClientCredentials) Option {
+	return func(c *Client) error {
+		if _, err := parseBaseURL(cc.TokenURL); err != nil {
+			return fmt.Errorf("%w: invalid token URL. %s", ErrInvalidOption, err)
+		}
+		if cc.ClientID == "" {
+			return fmt.Errorf("%w: empty client ID", ErrInvalidOption)
+		}
+		if cc.ExpiryDelta < 0 {
+			return fmt.Errorf("%w: negative expiry delta", ErrInvalidOption)
+		}
+		if cc.ExpiryDelta == 0 {
+			cc.ExpiryDelta = defaultExpiryDelta
+		}
+		if cc.HTTPClient == nil {
+			cc.HTTPClient = http.DefaultClient
+		}
+
+		return WithMiddleware((&tokenSource{cc: cc}).middleware())(c)
+	}
+}
+
+// middleware returns a Middleware which authenticates every request with a bearer token of this tokenSource.
+func (ts *tokenSource) middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			tok, err := ts.get(req.Context(), "")
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+tok)
+
+			resp, err := next.Do(req)
+			if err != nil || resp.StatusCode != http.StatusUnauthorized {
+				return resp, err
+			}
+
+			// The token may have been revoked or expired early: retry once with a fresh one
+			retry, err := rewind(req)
+			if err != nil {
+				return resp, nil
+			}
+			_ = resp.Body.Close()
+
+			tok, err = ts.get(req.Context(), tok)
+			if err != nil {
+				return nil, err
+			}
+			retry.Header.Set("Authorization", "Bearer "+tok)
+
+			return next.Do(retry)
+		})
+	}
+}
+
+// get returns the cached token, unless it is about to expire or it is the given rejected token. Otherwise, it requests
+// a new token. Concurrent callers wait for the same request.
+func (ts *tokenSource) get(ctx context.Context, rejected string) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	valid := ts.token != "" && ts.token != rejected &&
+		(ts.expiry.IsZero() || time.Now().Add(ts.cc.ExpiryDelta).Before(ts.expiry))
+	if valid {
+		return ts.token, nil
+	}
+
+	tok, err := ts.request(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	ts.token = tok.AccessToken
+	ts.expiry = time.Time{}
+	if tok.ExpiresIn > 0 {
+		ts.expiry = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	}
+	return ts.token, nil
+}
+
+// request requests a new token to the token endpoint.
+func (ts *tokenSource) request(ctx context.Context) (*token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(ts.cc.Scopes) > 0 {
+		form.Set("scope", strings.Join(ts.cc.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.cc.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(ts.cc.ClientID), url.QueryEscape(ts.cc.ClientSecret))
+
+	resp, err := ts.cc.HTTPClient.Do(req)
+	if err != nil {
+		return nil, contextErr(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, contextErr(ctx, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: token endpoint returned %d", ErrUnauthorized, resp.StatusCode)
+	}
+
+	var tok token
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return nil, fmt.Errorf("%w: invalid token response. %s", ErrUnauthorized, err)
+	}
+	if tok.AccessToken == "" {
+		return nil, fmt.Errorf("%w: no access token in the token response", ErrUnauthorized)
+	}
+	if tok.TokenType != "" && !strings.EqualFold(tok.TokenType, "bearer") {
+		return nil, fmt.Errorf("%w: unsupported token type %q", ErrUnauthorized, tok.TokenType)
+	}
+	return &tok, nil
+}
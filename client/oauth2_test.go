@@ -0,0 +1,160 @@
+// +build unit
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// setupTokenServer returns a mocked token endpoint which issues the tokens "token-1", "token-2", ... valid for the
+// given expiresIn seconds, along with the number of issued tokens.
+func setupTokenServer(t *testing.T, expiresIn int64) (*httptest.Server, *int32) {
+	var issued int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.NoError(t, req.ParseForm())
+		assert.Equal(t, "client_credentials", req.PostForm.Get("grant_type"))
+		assert.Equal(t, "accounts:read accounts:write", req.PostForm.Get("scope"))
+
+		id, secret, ok := req.BasicAuth()
+		if !ok || id != "my-client" || secret != "my-secret" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// Let the concurrent callers pile up
+		time.Sleep(10 * time.Millisecond)
+
+		n := atomic.AddInt32(&issued, 1)
+		serveContent(t, rw, http.StatusOK, token{
+			AccessToken: fmt.Sprintf("token-%d", n),
+			TokenType:   "Bearer",
+			ExpiresIn:   expiresIn,
+		})
+	}))
+
+	return server, &issued
+}
+
+// setupProtectedServer returns a mocked Accounts API which only accepts the given tokens.
+func setupProtectedServer(t *testing.T, accepted ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		for _, tok := range accepted {
+			if req.Header.Get("Authorization") == "Bearer "+tok {
+				serveContent(t, rw, http.StatusOK, AccountResource{Data: accountOne})
+				return
+			}
+		}
+		serveError(t, rw, http.StatusUnauthorized)
+	}))
+}
+
+func credentialsOf(tokenURL string) ClientCredentials {
+	return ClientCredentials{
+		TokenURL:     tokenURL,
+		ClientID:     "my-client",
+		ClientSecret: "my-secret",
+		Scopes:       []string{"accounts:read", "accounts:write"},
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	type testData struct {
+		expiresIn int64
+		accepted  []string
+		calls     int
+		issued    int32
+		err       error
+	}
+
+	var golds = []testData{
+		// The token is cached
+		0: {3600, []string{"token-1"}, 3, 1, nil},
+		// The token is refreshed shortly before its expiry
+		1: {5, []string{"token-1", "token-2", "token-3"}, 3, 3, nil},
+		// The rejected token is refreshed once
+		2: {3600, []string{"token-2"}, 2, 2, nil},
+		// The refreshed token is rejected too
+		3: {3600, []string{}, 1, 2, ErrUnauthorized},
+	}
+
+	for i, g := range golds {
+		tokenServer, issued := setupTokenServer(t, g.expiresIn)
+		server := setupProtectedServer(t, g.accepted...)
+
+		c, err := New(server.URL, WithClientCredentials(credentialsOf(tokenServer.URL)))
+		assert.NoError(t, err)
+
+		for call := 0; call < g.calls; call++ {
+			_, err = c.Fetch(accountOne.ID)
+		}
+
+		assert.True(t, errors.Is(err, g.err), fmt.Sprintf("%d. Want error %+v, but got %+v", i, g.err, err))
+		assert.Equal(t, g.issued, atomic.LoadInt32(issued), fmt.Sprintf("%d. Unexpected number of tokens", i))
+
+		server.Close()
+		tokenServer.Close()
+	}
+}
+
+func TestClientCredentialsConcurrent(t *testing.T) {
+	tokenServer, issued := setupTokenServer(t, 3600)
+	defer tokenServer.Close()
+	server := setupProtectedServer(t, "token-1")
+	defer server.Close()
+
+	c, err := New(server.URL, WithClientCredentials(credentialsOf(tokenServer.URL)))
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.FetchContext(context.Background(), accountOne.ID)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(issued))
+}
+
+func TestClientCredentialsTokenError(t *testing.T) {
+	tokenServer, _ := setupTokenServer(t, 3600)
+	defer tokenServer.Close()
+
+	cc := credentialsOf(tokenServer.URL)
+	cc.ClientSecret = "wrong"
+
+	c, err := New("http://localhost:8080", WithClientCredentials(cc))
+	assert.NoError(t, err)
+
+	_, err = c.Fetch(accountOne.ID)
+
+	assert.True(t, errors.Is(err, ErrUnauthorized), fmt.Sprintf("Want error %+v, but got %+v", ErrUnauthorized, err))
+}
+
+func TestWithClientCredentials(t *testing.T) {
+	var golds = []ClientCredentials{
+		0: {TokenURL: "not a url", ClientID: "my-client"},
+		1: {TokenURL: "http://localhost:8080/token"},
+		2: {TokenURL: "http://localhost:8080/token", ClientID: "my-client", ExpiryDelta: -time.Second},
+	}
+
+	for i, g := range golds {
+		_, err := New("http://localhost:8080", WithClientCredentials(g))
+
+		assert.True(t, errors.Is(err, ErrInvalidOption), fmt.Sprintf("%d. Want error %+v, but got %+v", i, ErrInvalidOption, err))
+	}
+}
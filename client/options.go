@@ -0,0 +1,105 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option represents a functional option to configure a Client. Use it in the function New().
+type Option func(c *Client) error
+
+// New returns a new Client against the Accounts API available at the given baseURL, configured with the given options
+// opts. The baseURL must be an absolute HTTP(S) URL. The Client retries the failed calls using DefaultRetryPolicy,
+// unless told otherwise with WithRetryPolicy().
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := parseBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	retry := DefaultRetryPolicy
+	c := &Client{
+		BaseURL:    u,
+		httpClient: &http.Client{},
+		retry:      &retry,
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	// The timeout applies to the final HTTP client, whatever the order of the options
+	if c.timeout != nil {
+		hc := *c.httpClient
+		hc.Timeout = *c.timeout
+		c.httpClient = &hc
+	}
+
+	return c, nil
+}
+
+// WithHTTPClient makes the Client use the given HTTP client hc to send the requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) error {
+		if hc == nil {
+			return fmt.Errorf("%w: nil HTTP client", ErrInvalidOption)
+		}
+		c.httpClient = hc
+		return nil
+	}
+}
+
+// WithTimeout sets the given timeout d to every request sent by the Client.
+// The HTTP client given in WithHTTPClient, if any, is not modified, but a copy of it is used instead.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d < 0 {
+			return fmt.Errorf("%w: negative timeout %s", ErrInvalidOption, d)
+		}
+		c.timeout = &d
+		return nil
+	}
+}
+
+// WithUserAgent overrides the default User-Agent header sent by the Client.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) error {
+		if ua == "" {
+			return fmt.Errorf("%w: empty user agent", ErrInvalidOption)
+		}
+		c.userAgent = ua
+		return nil
+	}
+}
+
+// WithHeader adds the given header key and value to every request sent by the Client.
+func WithHeader(key, value string) Option {
+	return func(c *Client) error {
+		if key == "" {
+			return fmt.Errorf("%w: empty header key", ErrInvalidOption)
+		}
+		if c.header == nil {
+			c.header = make(http.Header)
+		}
+		c.header.Add(key, value)
+		return nil
+	}
+}
+
+func parseBaseURL(baseURL string) (*url.URL, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBaseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: unsupported scheme in %q", ErrInvalidBaseURL, baseURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%w: missing host in %q", ErrInvalidBaseURL, baseURL)
+	}
+	return u, nil
+}
@@ -0,0 +1,83 @@
+// +build unit
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	type testData struct {
+		baseURL string
+		opts    []Option
+		err     error
+	}
+
+	var golds = []testData{
+		0: {"http://localhost:8080", nil, nil},
+		1: {"https://api.form3.tech/", []Option{WithTimeout(time.Second), WithUserAgent("ua")}, nil},
+		2: {"", nil, ErrInvalidBaseURL},
+		3: {"localhost:8080", nil, ErrInvalidBaseURL},
+		4: {"ftp://localhost", nil, ErrInvalidBaseURL},
+		5: {"http://", nil, ErrInvalidBaseURL},
+		6: {"http://localhost:8080", []Option{WithHTTPClient(nil)}, ErrInvalidOption},
+		7: {"http://localhost:8080", []Option{WithTimeout(-time.Second)}, ErrInvalidOption},
+		8: {"http://localhost:8080", []Option{WithUserAgent("")}, ErrInvalidOption},
+		9: {"http://localhost:8080", []Option{WithHeader("", "value")}, ErrInvalidOption},
+	}
+
+	for i, g := range golds {
+		c, err := New(g.baseURL, g.opts...)
+
+		assert.True(t, errors.Is(err, g.err), fmt.Sprintf("%d. Want error %+v, but got %+v", i, g.err, err))
+		assert.Equal(t, g.err == nil, c != nil, fmt.Sprintf("%d. Unexpected client %+v", i, c))
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	var got http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = req.Header
+		serveContent(t, rw, http.StatusOK, AccountResource{Data: accountOne})
+	}))
+	defer server.Close()
+
+	hc := &http.Client{}
+	c, err := New(server.URL,
+		WithHTTPClient(hc),
+		WithTimeout(5*time.Second),
+		WithUserAgent("my-service/1.0"),
+		WithHeader("X-Request-Id", "abc"),
+		WithHeader("X-Request-Id", "def"))
+	assert.NoError(t, err)
+
+	_, err = c.Fetch(accountOne.ID)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "my-service/1.0", got.Get("User-Agent"))
+	assert.Equal(t, []string{"abc", "def"}, got["X-Request-Id"])
+	assert.Equal(t, "application/vnd.api+json", got.Get("Accept"))
+	assert.Equal(t, 5*time.Second, c.httpClient.Timeout)
+	assert.Equal(t, time.Duration(0), hc.Timeout, "the given HTTP client must not be modified")
+
+	// The timeout applies whatever the order of the options
+	c, err = New(server.URL, WithTimeout(5*time.Second), WithHTTPClient(hc))
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, c.httpClient.Timeout)
+	assert.Equal(t, time.Duration(0), hc.Timeout, "the given HTTP client must not be modified")
+}
+
+func TestUnsetBaseURL(t *testing.T) {
+	var c Client
+
+	_, err := c.Fetch(accountOne.ID)
+
+	assert.True(t, errors.Is(err, ErrInvalidBaseURL), fmt.Sprintf("Want error %+v, but got %+v", ErrInvalidBaseURL, err))
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket which refills at a given rate of tokens per second, up to a given burst.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// WithRateLimit limits the requests sent by the Client to the given rate rps (requests per second), allowing bursts of
+// up to the given burst requests. Every attempt counts, including the retries.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) error {
+		if rps <= 0 {
+			return fmt.Errorf("%w: rate must be positive", ErrInvalidOption)
+		}
+		if burst < 1 {
+			return fmt.Errorf("%w: burst must be at least 1", ErrInvalidOption)
+		}
+		c.limiter = newRateLimiter(rps, burst)
+		return nil
+	}
+}
+
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available, unless the given context ctx is done earlier.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	// Reserve the token in advance; the deficit, if any, is the time to wait for it
+	l.tokens--
+	var d time.Duration
+	if l.tokens < 0 {
+		d = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if d == 0 {
+		return nil
+	}
+
+	if err := sleep(ctx, d); err != nil {
+		// Give the reserved token back
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
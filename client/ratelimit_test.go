@@ -0,0 +1,152 @@
+// +build unit
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimited(t *testing.T) {
+	type testData struct {
+		retryAfter string
+		want       time.Duration
+	}
+
+	var golds = []testData{
+		0: {"", 0},
+		1: {"30", 30 * time.Second},
+		2: {"not-a-duration", 0},
+		3: {time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0},
+	}
+
+	for i, g := range golds {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if g.retryAfter != "" {
+				rw.Header().Set("Retry-After", g.retryAfter)
+			}
+			serveError(t, rw, http.StatusTooManyRequests)
+		}))
+
+		client := setupClient(t, server.URL)
+
+		_, err := client.Fetch(accountOne.ID)
+		server.Close()
+
+		var apiErr *APIError
+		assert.True(t, errors.Is(err, ErrRateLimited), fmt.Sprintf("%d. Want error %+v, but got %+v", i, ErrRateLimited, err))
+		assert.False(t, errors.Is(err, ErrBadInput), fmt.Sprintf("%d. Unexpected error %+v", i, err))
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, g.want, apiErr.RetryAfter, fmt.Sprintf("%d. Want Retry-After %s, but got %s", i, g.want, apiErr.RetryAfter))
+	}
+}
+
+func TestRateLimitedRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		attempts++
+		if attempts == 1 {
+			rw.Header().Set("Retry-After", "1")
+			serveError(t, rw, http.StatusTooManyRequests)
+			return
+		}
+		serveContent(t, rw, http.StatusOK, AccountResource{Data: accountOne})
+	}))
+	defer server.Close()
+
+	policy := fastRetryPolicy
+	policy.MaxBackoff = 2 * time.Second
+	policy.RetryStatuses = []int{http.StatusTooManyRequests}
+	client, err := New(server.URL, WithRetryPolicy(policy))
+	assert.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.Fetch(accountOne.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.True(t, time.Since(start) >= time.Second, "the Retry-After header must be respected")
+}
+
+func TestRateLimitedRetryTooLong(t *testing.T) {
+	type testData struct {
+		retryAfter string
+		timeout    time.Duration
+	}
+
+	var golds = []testData{
+		// Longer than the max backoff
+		0: {"3600", 0},
+		// Beyond the deadline of the call
+		1: {"1", 300 * time.Millisecond},
+	}
+
+	for i, g := range golds {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			attempts++
+			rw.Header().Set("Retry-After", g.retryAfter)
+			serveError(t, rw, http.StatusTooManyRequests)
+		}))
+
+		policy := fastRetryPolicy
+		policy.MaxBackoff = 2 * time.Second
+		client, err := New(server.URL, WithRetryPolicy(policy))
+		assert.NoError(t, err)
+
+		ctx, cancel := context.Background(), func() {}
+		if g.timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		}
+
+		start := time.Now()
+		_, err = client.FetchContext(ctx, accountOne.ID)
+		cancel()
+		server.Close()
+
+		// The call gives up at once, with the requested wait
+		var apiErr *APIError
+		assert.True(t, errors.Is(err, ErrRateLimited), fmt.Sprintf("%d. Want error %+v, but got %+v", i, ErrRateLimited, err))
+		assert.True(t, errors.As(err, &apiErr))
+		assert.NotZero(t, apiErr.RetryAfter, fmt.Sprintf("%d. Want Retry-After", i))
+		assert.Equal(t, 1, attempts, fmt.Sprintf("%d. Unexpected number of attempts", i))
+		assert.True(t, time.Since(start) < 200*time.Millisecond, fmt.Sprintf("%d. The call must not wait", i))
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(100, 2)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, l.wait(context.Background()))
+	}
+	elapsed := time.Since(start)
+
+	// The burst is served at once, the remaining 4 requests at 100 rps
+	assert.True(t, elapsed >= 35*time.Millisecond, fmt.Sprintf("Want at least 40ms, but got %s", elapsed))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l = newRateLimiter(1, 1)
+	assert.NoError(t, l.wait(ctx))
+	assert.True(t, errors.Is(l.wait(ctx), context.Canceled))
+}
+
+func TestWithRateLimit(t *testing.T) {
+	_, err := New("http://localhost:8080", WithRateLimit(0, 1))
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+
+	_, err = New("http://localhost:8080", WithRateLimit(10, 0))
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+
+	c, err := New("http://localhost:8080", WithRateLimit(10, 5))
+	assert.NoError(t, err)
+	assert.NotNil(t, c.limiter)
+}
@@ -0,0 +1,211 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RetryPolicy represents how the Client retries the failed calls to the Accounts API.
+// Only the calls which are safe to repeat are retried: Fetch, List and Delete (versioned, hence idempotent), and Create,
+// whose conflict on a retry is resolved by fetching the existing account and comparing it with the requested one.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one. Use 1 to disable the retries.
+	MaxAttempts int
+
+	// BaseBackoff is the wait before the first retry. It doubles after each retry, up to MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	// Jitter is the fraction of the backoff, between 0 and 1, which is randomly subtracted on each retry.
+	Jitter float64
+
+	// RetryStatuses are the response status codes to retry. A Retry-After header in these responses takes precedence
+	// over the backoff, when it asks for a longer wait. The call is not retried when that wait exceeds MaxBackoff or
+	// outlasts the deadline of the call: its APIError is returned instead, with the requested wait in RetryAfter.
+	RetryStatuses []int
+
+	// RetryError reports whether the given transport error err should be retried.
+	// It defaults to IsTemporaryError when nil.
+	RetryError func(err error) bool
+}
+
+// DefaultRetryPolicy is the RetryPolicy used by the clients created with New().
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseBackoff: 100 * time.Millisecond,
+	MaxBackoff:  2 * time.Second,
+	Jitter:      0.5,
+	RetryStatuses: []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// noRetry is the RetryPolicy used when none is configured or the operation is not safe to repeat.
+var noRetry = RetryPolicy{MaxAttempts: 1}
+
+// operation describes a single call of the Client, which may span several attempts.
+type operation struct {
+	name      string
+	route     string
+	accountID string
+	retryable bool
+	attempts  int
+
+	// lastStatus and lastErr tell how the previous attempt ended, either with a response status or an error.
+	lastStatus int
+	lastErr    error
+}
+
+// previousMayHaveApplied reports whether the previous attempt, if any, may have been processed by the service even
+// though its outcome got lost: it ended with a transport error or a gateway failure. A rate limited attempt never was.
+func (op *operation) previousMayHaveApplied() bool {
+	if op.attempts < 2 {
+		return false
+	}
+	if op.lastErr != nil {
+		return !errors.Is(op.lastErr, ErrCircuitOpen)
+	}
+	switch op.lastStatus {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// WithRetryPolicy makes the Client retry the failed calls according to the given policy p.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) error {
+		if p.MaxAttempts < 1 {
+			return fmt.Errorf("%w: max attempts must be at least 1", ErrInvalidOption)
+		}
+		if p.BaseBackoff < 0 || p.MaxBackoff < p.BaseBackoff {
+			return fmt.Errorf("%w: invalid backoff range [%s, %s]", ErrInvalidOption, p.BaseBackoff, p.MaxBackoff)
+		}
+		if p.Jitter < 0 || p.Jitter > 1 {
+			return fmt.Errorf("%w: jitter must be between 0 and 1", ErrInvalidOption)
+		}
+		c.retry = &p
+		return nil
+	}
+}
+
+// IsTemporaryError reports whether the given transport error err is worth retrying: connection failures, resets and
+// timeouts are, whereas context cancellations and malformed requests are not.
+func IsTemporaryError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// url.Error implements net.Error itself, so look into the underlying error
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func (c *Client) retryPolicyFor(op *operation) *RetryPolicy {
+	if c.retry == nil || !op.retryable {
+		return &noRetry
+	}
+	return c.retry
+}
+
+// shouldRetry reports whether the outcome of an attempt, either the response resp or the error err, is worth retrying.
+func (p *RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		if p.RetryError != nil {
+			return p.RetryError(err)
+		}
+		return IsTemporaryError(err)
+	}
+
+	for _, status := range p.RetryStatuses {
+		if resp.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait before the retry following the given attempt number.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+	return d
+}
+
+// wait returns the wait before the retry following the given attempt number, which ended with the response resp.
+// It reports false when the wait requested in the Retry-After header is too long to be honoured, either longer than
+// MaxBackoff or beyond the deadline of the given context ctx.
+func (p *RetryPolicy) wait(ctx context.Context, attempt int, resp *http.Response) (time.Duration, bool) {
+	d := p.backoff(attempt)
+	if resp == nil {
+		return d, true
+	}
+
+	ra, ok := retryAfter(resp)
+	if !ok || ra <= d {
+		return d, true
+	}
+	if ra > p.MaxBackoff {
+		return 0, false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(ra).After(deadline) {
+		return 0, false
+	}
+	return ra, true
+}
+
+// sleep waits for the given duration d, unless the given context ctx is done earlier.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rewind returns a copy of the given request req, ready to be sent again.
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
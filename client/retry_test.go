@@ -0,0 +1,275 @@
+// +build unit
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fastRetryPolicy is a retry policy with tiny backoffs to keep the tests fast.
+var fastRetryPolicy = RetryPolicy{
+	MaxAttempts:   3,
+	BaseBackoff:   time.Millisecond,
+	MaxBackoff:    5 * time.Millisecond,
+	RetryStatuses: []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+	},
+}
+
+// hangUp is a pseudo status code used to simulate in the mock test server a dropped connection.
+const hangUp = -1
+
+func TestRetry(t *testing.T) {
+	type testData struct {
+		// responses holds the status codes served in sequence per request method
+		responses map[string][]int
+		// existing is the account served to a GET request once the responses are exhausted
+		existing Account
+		call     func(c *Client) error
+		attempts map[string]int
+		err      error
+	}
+
+	var (
+		create = func(c *Client) error {
+			_, err := c.Create(&AccountResource{Data: accountOne})
+			return err
+		}
+		fetch = func(c *Client) error {
+			_, err := c.Fetch(accountOne.ID)
+			return err
+		}
+		del = func(c *Client) error {
+			return c.Delete(accountOne.ID, accountOne.Version)
+		}
+	)
+
+	var golds = []testData{
+		0: {
+			map[string][]int{http.MethodGet: {http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK}},
+			accountOne,
+			fetch,
+			map[string]int{http.MethodGet: 3},
+			nil,
+		},
+		1: {
+			map[string][]int{http.MethodGet: {http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}},
+			accountOne,
+			fetch,
+			map[string]int{http.MethodGet: 3},
+			ErrServerError,
+		},
+		2: {
+			map[string][]int{http.MethodGet: {http.StatusBadRequest}},
+			accountOne,
+			fetch,
+			map[string]int{http.MethodGet: 1},
+			ErrBadInput,
+		},
+		3: {
+			map[string][]int{http.MethodGet: {hangUp, http.StatusOK}},
+			accountOne,
+			fetch,
+			map[string]int{http.MethodGet: 2},
+			nil,
+		},
+		4: {
+			map[string][]int{http.MethodPost: {http.StatusBadGateway, http.StatusConflict}, http.MethodGet: {http.StatusOK}},
+			accountOne,
+			create,
+			map[string]int{http.MethodPost: 2, http.MethodGet: 1},
+			nil,
+		},
+		5: {
+			map[string][]int{http.MethodPost: {http.StatusBadGateway, http.StatusConflict}, http.MethodGet: {http.StatusOK}},
+			accountTwo,
+			create,
+			map[string]int{http.MethodPost: 2, http.MethodGet: 1},
+			ErrConflict,
+		},
+		6: {
+			map[string][]int{http.MethodPost: {http.StatusConflict}},
+			accountOne,
+			create,
+			map[string]int{http.MethodPost: 1},
+			ErrConflict,
+		},
+		7: {
+			map[string][]int{http.MethodDelete: {http.StatusServiceUnavailable, http.StatusNotFound}},
+			accountOne,
+			del,
+			map[string]int{http.MethodDelete: 2},
+			nil,
+		},
+		8: {
+			map[string][]int{http.MethodDelete: {http.StatusNotFound}},
+			accountOne,
+			del,
+			map[string]int{http.MethodDelete: 1},
+			ErrNotFound,
+		},
+		// A rate limited attempt was never processed, so the retry outcome is the real one
+		9: {
+			map[string][]int{http.MethodDelete: {http.StatusTooManyRequests, http.StatusNotFound}},
+			accountOne,
+			del,
+			map[string]int{http.MethodDelete: 2},
+			ErrNotFound,
+		},
+		10: {
+			map[string][]int{http.MethodPost: {http.StatusTooManyRequests, http.StatusConflict}},
+			accountOne,
+			create,
+			map[string]int{http.MethodPost: 2},
+			ErrConflict,
+		},
+		// Only the previous attempt counts
+		11: {
+			map[string][]int{http.MethodDelete: {hangUp, http.StatusTooManyRequests, http.StatusNotFound}},
+			accountOne,
+			del,
+			map[string]int{http.MethodDelete: 3},
+			ErrNotFound,
+		},
+		12: {
+			map[string][]int{http.MethodDelete: {hangUp, http.StatusNotFound}},
+			accountOne,
+			del,
+			map[string]int{http.MethodDelete: 2},
+			nil,
+		},
+		// An internal server error may have happened after the account was created, but not after it was deleted
+		13: {
+			map[string][]int{http.MethodPost: {http.StatusInternalServerError, http.StatusConflict}, http.MethodGet: {http.StatusOK}},
+			accountOne,
+			create,
+			map[string]int{http.MethodPost: 2, http.MethodGet: 1},
+			nil,
+		},
+		14: {
+			map[string][]int{http.MethodPost: {http.StatusInternalServerError, http.StatusConflict}, http.MethodGet: {http.StatusOK}},
+			accountTwo,
+			create,
+			map[string]int{http.MethodPost: 2, http.MethodGet: 1},
+			ErrConflict,
+		},
+		15: {
+			map[string][]int{http.MethodDelete: {http.StatusInternalServerError, http.StatusNotFound}},
+			accountOne,
+			del,
+			map[string]int{http.MethodDelete: 2},
+			ErrNotFound,
+		},
+	}
+
+	var testRetry = func(t *testing.T, tc int, data testData) {
+		var mu sync.Mutex
+		attempts := make(map[string]int)
+
+		// Setup mocked server serving the given responses in sequence
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			n := attempts[req.Method]
+			attempts[req.Method]++
+			mu.Unlock()
+
+			status := http.StatusOK
+			if responses := data.responses[req.Method]; n < len(responses) {
+				status = responses[n]
+			}
+
+			switch {
+			case status == hangUp:
+				conn, _, err := rw.(http.Hijacker).Hijack()
+				assert.NoError(t, err)
+				assert.NoError(t, conn.Close())
+			case status == http.StatusOK && req.Method == http.MethodGet:
+				serveContent(t, rw, status, AccountResource{Data: data.existing})
+			case status == http.StatusNoContent || status == http.StatusCreated:
+				serveContent(t, rw, status, AccountResource{Data: data.existing})
+			case status == http.StatusTooManyRequests:
+				rw.Header().Set("Retry-After", "0")
+				serveError(t, rw, status)
+			default:
+				serveError(t, rw, status)
+			}
+		}))
+		defer server.Close()
+
+		// Setup client
+		client, err := New(server.URL, WithRetryPolicy(fastRetryPolicy))
+		assert.NoError(t, err)
+
+		err = data.call(client)
+
+		assert.True(t, errors.Is(err, data.err), fmt.Sprintf("%d. Want error %+v, but got %+v", tc, data.err, err))
+		assert.Equal(t, data.attempts, attempts, fmt.Sprintf("%d. Want attempts %+v, but got %+v", tc, data.attempts, attempts))
+	}
+
+	for i, g := range golds {
+		testRetry(t, i, g)
+	}
+}
+
+func TestRetryDisabled(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		attempts++
+		serveError(t, rw, http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := setupClient(t, server.URL)
+
+	_, err := client.Fetch(accountOne.ID)
+
+	assert.True(t, errors.Is(err, ErrServerError))
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	type testData struct {
+		policy   RetryPolicy
+		attempt  int
+		min, max time.Duration
+	}
+
+	var golds = []testData{
+		0: {RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}, 1, 100 * time.Millisecond, 100 * time.Millisecond},
+		1: {RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}, 3, 400 * time.Millisecond, 400 * time.Millisecond},
+		2: {RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}, 10, time.Second, time.Second},
+		3: {RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}, 100, time.Second, time.Second},
+		4: {RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: 0.5}, 2, 100 * time.Millisecond, 200 * time.Millisecond},
+	}
+
+	for i, g := range golds {
+		for n := 0; n < 10; n++ {
+			got := g.policy.backoff(g.attempt)
+			assert.True(t, got >= g.min && got <= g.max, fmt.Sprintf("%d. Want backoff in [%s, %s], but got %s", i, g.min, g.max, got))
+		}
+	}
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	var golds = []RetryPolicy{
+		0: {MaxAttempts: 0},
+		1: {MaxAttempts: 2, BaseBackoff: time.Second, MaxBackoff: time.Millisecond},
+		2: {MaxAttempts: 2, Jitter: 1.5},
+	}
+
+	for i, g := range golds {
+		_, err := New("http://localhost:8080", WithRetryPolicy(g))
+
+		assert.True(t, errors.Is(err, ErrInvalidOption), fmt.Sprintf("%d. Want error %+v, but got %+v", i, ErrInvalidOption, err))
+	}
+}
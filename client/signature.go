@@ -0,0 +1,170 @@
+package client
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// signedHeaders are the headers covered by the signature, in order.
+var signedHeaders = []string{"(request-target)", "host", "date", "digest"}
+
+// Signer signs the requests as per the HTTP Signatures draft (draft-cavage-http-signatures) required by the Form3 API.
+// The signature covers the request target, the Host, Date and Digest headers, using either an RSA or an ECDSA key.
+type Signer struct {
+	keyID     string
+	key       crypto.Signer
+	algorithm string
+
+	// now returns the current time used in the Date header. It is only overridden in the tests.
+	now func() time.Time
+}
+
+// NewSigner returns a Signer which signs with the given PEM encoded private key, referenced in the signatures by the
+// given keyID. The key may be an RSA key (PKCS #1 or PKCS #8) or an ECDSA key (SEC 1 or PKCS #8).
+func NewSigner(keyID string, pemKey []byte) (*Signer, error) {
+	if keyID == "" {
+		return nil, fmt.Errorf("%w: empty key ID", ErrInvalidKey)
+	}
+
+	block, _ := pem.Decode(pemKey)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM data found", ErrInvalidKey)
+	}
+
+	key, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Signer{keyID: keyID, key: key, now: time.Now}
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		s.algorithm = "rsa-sha256"
+	case *ecdsa.PrivateKey:
+		s.algorithm = "ecdsa-sha256"
+	}
+	return s, nil
+}
+
+// WithSignature makes the Client sign every request with the given PEM encoded private key, referenced by the given
+// keyID. See NewSigner().
+func WithSignature(keyID string, pemKey []byte) Option {
+	return func(c *Client) error {
+		s, err := NewSigner(keyID, pemKey)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidOption, err)
+		}
+		return WithMiddleware(s.Middleware())(c)
+	}
+}
+
+// Middleware returns a Middleware which signs every request with this Signer.
+func (s *Signer) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if err := s.Sign(req); err != nil {
+				return nil, err
+			}
+			return next.Do(req)
+		})
+	}
+}
+
+// Sign adds the Date (unless already set), Digest and Signature headers to the given request req.
+func (s *Signer) Sign(req *http.Request) error {
+	body, err := bodyOf(req)
+	if err != nil {
+		return err
+	}
+
+	if req.Header.Get("Date") == "" {
+		req.Header.Set("Date", s.now().UTC().Format(http.TimeFormat))
+	}
+	sum := sha256.Sum256(body)
+	req.Header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]))
+
+	hashed := sha256.Sum256([]byte(signingString(req)))
+	sig, err := s.key.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		s.keyID, s.algorithm, strings.Join(signedHeaders, " "), base64.StdEncoding.EncodeToString(sig)))
+	return nil
+}
+
+// signingString returns the string to sign for the given request req: one "name: value" line per signed header.
+func signingString(req *http.Request) string {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	lines := []string{
+		fmt.Sprintf("(request-target): %s %s", strings.ToLower(req.Method), req.URL.RequestURI()),
+		"host: " + host,
+		"date: " + req.Header.Get("Date"),
+		"digest: " + req.Header.Get("Digest"),
+	}
+	return strings.Join(lines, "\n")
+}
+
+// bodyOf returns the body of the given request req, leaving it available to be sent.
+func bodyOf(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// parsePrivateKey parses the given DER encoded RSA or ECDSA private key.
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported private key", ErrInvalidKey)
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported private key type %T", ErrInvalidKey, key)
+	}
+}
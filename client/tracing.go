@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The attributes set on the spans of the Client, named after the OpenTelemetry semantic conventions where they exist.
+const (
+	AttrHTTPMethod     = "http.request.method"
+	AttrHTTPRoute      = "http.route"
+	AttrHTTPStatusCode = "http.response.status_code"
+	AttrResendCount    = "http.request.resend_count"
+	AttrAccountID      = "accountapi.account_id"
+)
+
+// Tracer starts the spans of the calls of the Client. It is a small subset of the OpenTelemetry tracer, so that an
+// adapter to any tracing SDK is a few lines long. Its implementations must be safe for concurrent use.
+type Tracer interface {
+	// Start starts a span with the given name, as a child of the span in the given context ctx, if any. It returns a
+	// context holding the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span represents a traced call of the Client.
+type Span interface {
+	// SetAttribute sets the attribute key to the given value, either a string, an int or a bool.
+	SetAttribute(key string, value interface{})
+
+	// SetError marks the span as failed with the given error err.
+	SetError(err error)
+
+	// End ends the span.
+	End()
+
+	// SpanContext returns the identifiers of the span, propagated to the Accounts API in the traceparent header.
+	SpanContext() SpanContext
+}
+
+// SpanContext represents the identifiers of a span, as per the W3C Trace Context specification.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// IsValid reports whether both the trace and span identifiers are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceParent returns the W3C traceparent header value of the span context (e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01").
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), flags)
+}
+
+// WithTracer makes the Client trace its calls with the given tracer t. Each call emits a span named after the operation
+// ("create", "fetch", "list", "update" or "delete") spanning all its attempts, and every request carries the W3C
+// traceparent header of the span.
+func WithTracer(t Tracer) Option {
+	return func(c *Client) error {
+		if t == nil {
+			return fmt.Errorf("%w: nil tracer", ErrInvalidOption)
+		}
+		c.tracer = t
+		return nil
+	}
+}
+
+// startSpan starts the span of the operation op, and returns a copy of the given request req carrying it.
+func (c *Client) startSpan(op *operation, req *http.Request) (*http.Request, Span) {
+	ctx, span := c.tracer.Start(req.Context(), op.name)
+
+	span.SetAttribute(AttrHTTPMethod, req.Method)
+	span.SetAttribute(AttrHTTPRoute, op.route)
+	if op.accountID != "" {
+		span.SetAttribute(AttrAccountID, op.accountID)
+	}
+
+	req = req.WithContext(ctx)
+	if sc := span.SpanContext(); sc.IsValid() {
+		req.Header.Set("traceparent", sc.TraceParent())
+	}
+	return req, span
+}
+
+// endSpan ends the given span of the operation op, which ended with either the response resp or the error err.
+// As per the OpenTelemetry conventions for client spans, the 4xx and 5xx responses mark the span as failed.
+func endSpan(span Span, op *operation, resp *http.Response, err error) {
+	if op.attempts > 1 {
+		span.SetAttribute(AttrResendCount, op.attempts-1)
+	}
+
+	if err != nil {
+		span.SetError(err)
+	} else if resp != nil {
+		span.SetAttribute(AttrHTTPStatusCode, resp.StatusCode)
+		if resp.StatusCode >= http.StatusBadRequest {
+			span.SetError(fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)))
+		}
+	}
+
+	span.End()
+}
+
+// InMemoryTracer is a Tracer which records the spans in memory, so that they can be checked in the tests.
+type InMemoryTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+// RecordedSpan represents a span recorded by InMemoryTracer.
+type RecordedSpan struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanContext
+	Attributes map[string]interface{}
+	Err        error
+	Start      time.Time
+	End        time.Time
+	Ended      bool
+}
+
+// recordedSpan is the Span started by InMemoryTracer.
+type recordedSpan struct {
+	tracer *InMemoryTracer
+	data   RecordedSpan
+}
+
+// spanKey is the context key of the span started by InMemoryTracer.
+type spanKey struct{}
+
+// NewInMemoryTracer returns an InMemoryTracer with no spans.
+func NewInMemoryTracer() *InMemoryTracer {
+	return &InMemoryTracer{}
+}
+
+// Start implements Tracer. The new span belongs to the trace of its parent, if any, or to a new sampled trace.
+func (t *InMemoryTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := &recordedSpan{
+		tracer: t,
+		data: RecordedSpan{
+			Name:       name,
+			Attributes: make(map[string]interface{}),
+			Start:      time.Now(),
+		},
+	}
+
+	if parent, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		s.data.Parent = parent.SpanContext()
+		s.data.Context.TraceID = s.data.Parent.TraceID
+		s.data.Context.Sampled = s.data.Parent.Sampled
+	} else {
+		randomID(s.data.Context.TraceID[:])
+		s.data.Context.Sampled = true
+	}
+	randomID(s.data.Context.SpanID[:])
+
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// Spans returns a snapshot of the recorded spans, in the order they were started.
+func (t *InMemoryTracer) Spans() []RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	spans := make([]RecordedSpan, len(t.spans))
+	for i, s := range t.spans {
+		spans[i] = s.data
+		spans[i].Attributes = make(map[string]interface{}, len(s.data.Attributes))
+		for k, v := range s.data.Attributes {
+			spans[i].Attributes[k] = v
+		}
+	}
+	return spans
+}
+
+// SetAttribute implements Span.
+func (s *recordedSpan) SetAttribute(key string, value interface{}) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.data.Attributes[key] = value
+}
+
+// SetError implements Span.
+func (s *recordedSpan) SetError(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.data.Err = err
+}
+
+// End implements Span.
+func (s *recordedSpan) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.data.End = time.Now()
+	s.data.Ended = true
+}
+
+// SpanContext implements Span.
+func (s *recordedSpan) SpanContext() SpanContext {
+	return s.data.Context
+}
+
+// randomID fills the given identifier b with random bytes.
+func randomID(b []byte) {
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		panic(fmt.Sprintf("failed to generate ID. %s", err))
+	}
+}
@@ -0,0 +1,124 @@
+// +build unit
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusNotFound}
+	calls := 0
+	var traceParents []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		traceParents = append(traceParents, req.Header.Get("traceparent"))
+		status := statuses[calls]
+		calls++
+		if status == http.StatusOK {
+			serveContent(t, rw, status, AccountResource{Data: accountOne})
+		} else {
+			serveError(t, rw, status)
+		}
+	}))
+	defer server.Close()
+
+	tracer := NewInMemoryTracer()
+	c, err := New(server.URL,
+		WithTracer(tracer),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, RetryStatuses: []int{http.StatusServiceUnavailable}}))
+	assert.NoError(t, err)
+
+	// The spans of the calls are children of the span in the context, if any
+	ctx, parent := tracer.Start(context.Background(), "parent")
+
+	_, err = c.FetchContext(ctx, accountOne.ID)
+	assert.NoError(t, err)
+	err = c.DeleteContext(context.Background(), accountTwo.ID, 0)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	spans := tracer.Spans()
+	assert.Len(t, spans, 3)
+
+	fetch := spans[1]
+	assert.Equal(t, "fetch", fetch.Name)
+	assert.True(t, fetch.Ended)
+	assert.NoError(t, fetch.Err)
+	assert.Equal(t, parent.SpanContext(), fetch.Parent)
+	assert.Equal(t, parent.SpanContext().TraceID, fetch.Context.TraceID)
+	assert.Equal(t, map[string]interface{}{
+		AttrHTTPMethod:     http.MethodGet,
+		AttrHTTPRoute:      "/v1/organisation/accounts/{id}",
+		AttrHTTPStatusCode: http.StatusOK,
+		AttrResendCount:    1,
+		AttrAccountID:      accountOne.ID,
+	}, fetch.Attributes)
+
+	del := spans[2]
+	assert.Equal(t, "delete", del.Name)
+	assert.True(t, del.Ended)
+	assert.Error(t, del.Err)
+	assert.Equal(t, SpanContext{}, del.Parent)
+	assert.Equal(t, map[string]interface{}{
+		AttrHTTPMethod:     http.MethodDelete,
+		AttrHTTPRoute:      "/v1/organisation/accounts/{id}",
+		AttrHTTPStatusCode: http.StatusNotFound,
+		AttrAccountID:      accountTwo.ID,
+	}, del.Attributes)
+
+	// Every attempt carries the traceparent of the span of its call
+	assert.Equal(t, []string{
+		fetch.Context.TraceParent(),
+		fetch.Context.TraceParent(),
+		del.Context.TraceParent(),
+	}, traceParents)
+}
+
+func TestTracingTransportError(t *testing.T) {
+	tracer := NewInMemoryTracer()
+	c, err := New("http://127.0.0.1:1", WithTracer(tracer), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	assert.NoError(t, err)
+
+	_, err = c.ListContext(context.Background(), nil)
+	assert.Error(t, err)
+
+	spans := tracer.Spans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "list", spans[0].Name)
+	assert.Equal(t, err, spans[0].Err)
+	assert.Equal(t, "/v1/organisation/accounts", spans[0].Attributes[AttrHTTPRoute])
+	assert.NotContains(t, spans[0].Attributes, AttrHTTPStatusCode)
+	assert.NotContains(t, spans[0].Attributes, AttrAccountID)
+}
+
+func TestTraceParent(t *testing.T) {
+	type testData struct {
+		sc   SpanContext
+		want string
+	}
+
+	traceID := [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanID := [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+
+	var golds = []testData{
+		0: {SpanContext{traceID, spanID, true}, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		1: {SpanContext{traceID, spanID, false}, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+	}
+
+	for i, g := range golds {
+		assert.True(t, g.sc.IsValid())
+		assert.Equal(t, g.want, g.sc.TraceParent(), fmt.Sprintf("%d. Unexpected traceparent", i))
+	}
+	assert.False(t, SpanContext{TraceID: traceID}.IsValid())
+}
+
+func TestWithTracer(t *testing.T) {
+	_, err := New("http://localhost:8080", WithTracer(nil))
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+}
@@ -0,0 +1,216 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"gitlab.com/kitolabs-private/form3/interview-accountapi/client/iban"
+	"regexp"
+	"strings"
+)
+
+// FieldError represents a single invalid field of an account, named after its JSON path (e.g. "attributes.bank_id").
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError represents all the invalid fields of an account, as reported by Account.Validate().
+// It wraps ErrInvalidAccount, so it can be matched using errors.Is().
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidAccount, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns ErrInvalidAccount.
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidAccount
+}
+
+// countryRule represents the Form3 validation rules of the accounts held in a given country.
+// A nil pattern means the field is not allowed for the country.
+type countryRule struct {
+	bankIDCode     BankIDCode
+	bankID         *regexp.Regexp
+	bankIDRequired bool
+	bicRequired    bool
+	accountNumber  *regexp.Regexp
+	ibanAllowed    bool
+}
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	bicPattern  = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+)
+
+// countryRules are the per-country rules of the Form3 specifications, keyed by ISO 3166-1 alpha-2 country code.
+var countryRules = map[Country]countryRule{
+	CountryGB: {BankIDCodeGBDSC, regexp.MustCompile(`^[0-9]{6}$`), true, true, regexp.MustCompile(`^[0-9]{8}$`), true},
+	CountryAU: {BankIDCodeAUBSB, regexp.MustCompile(`^[0-9]{6}$`), false, true, regexp.MustCompile(`^[0-9]{6,10}$`), false},
+	CountryBE: {BankIDCodeBE, regexp.MustCompile(`^[0-9]{3}$`), true, false, regexp.MustCompile(`^[0-9]{7}$`), true},
+	CountryCA: {BankIDCodeCACPA, regexp.MustCompile(`^0[0-9]{8}$`), false, true, regexp.MustCompile(`^[0-9]{7,12}$`), false},
+	CountryFR: {BankIDCodeFR, regexp.MustCompile(`^[0-9]{10}$`), true, false, regexp.MustCompile(`^[0-9A-Z]{10}$`), true},
+	CountryDE: {BankIDCodeDEBLZ, regexp.MustCompile(`^[0-9]{8}$`), true, false, regexp.MustCompile(`^[0-9]{7,10}$`), true},
+	CountryGR: {BankIDCodeGRBIC, regexp.MustCompile(`^[0-9]{7}$`), true, false, regexp.MustCompile(`^[0-9]{16}$`), true},
+	CountryHK: {BankIDCodeHKNCC, regexp.MustCompile(`^[0-9]{3}$`), false, true, regexp.MustCompile(`^[0-9]{9,12}$`), false},
+	CountryIT: {BankIDCodeITNCC, regexp.MustCompile(`^[0-9A-Z]{10,11}$`), true, false, regexp.MustCompile(`^[0-9A-Z]{12}$`), true},
+	CountryLU: {BankIDCodeLULUX, regexp.MustCompile(`^[0-9]{3}$`), true, false, regexp.MustCompile(`^[0-9A-Z]{13}$`), true},
+	CountryNL: {"", nil, false, true, regexp.MustCompile(`^[0-9]{10}$`), true},
+	CountryPL: {BankIDCodePLKNR, regexp.MustCompile(`^[0-9]{8}$`), true, false, regexp.MustCompile(`^[0-9]{16}$`), true},
+	CountryPT: {BankIDCodePTNCC, regexp.MustCompile(`^[0-9]{8}$`), true, false, regexp.MustCompile(`^[0-9]{11}$`), true},
+	CountryES: {BankIDCodeESNCC, regexp.MustCompile(`^[0-9]{8}$`), true, false, regexp.MustCompile(`^[0-9]{10}$`), true},
+	CountryCH: {BankIDCodeCHBCC, regexp.MustCompile(`^[0-9]{5}$`), true, false, regexp.MustCompile(`^[0-9A-Z]{12}$`), true},
+	CountryUS: {BankIDCodeUSABA, regexp.MustCompile(`^[0-9]{9}$`), true, true, regexp.MustCompile(`^[0-9]{6,17}$`), false},
+}
+
+// WithValidation makes the Client validate the accounts with Account.Validate() before creating them, so that Create()
+// returns a ValidationError instead of sending an invalid account to the Accounts API.
+func WithValidation() Option {
+	return func(c *Client) error {
+		c.validate = true
+		return nil
+	}
+}
+
+// Validate checks the account against the Form3 specifications: the identifiers, the ISO 3166 country and ISO 4217
+// currency codes, the BIC format and the per-country rules of the bank ID, bank ID code, account number and IBAN. The
+// IBAN, if any, must also agree with the country, bank ID, account number and BIC.
+// It returns a ValidationError reporting every invalid field at once, or nil if the account is valid.
+func (a Account) Validate() error {
+	var v validator
+
+	if !uuidPattern.MatchString(a.ID) {
+		v.add("id", "must be a UUID")
+	}
+	if !uuidPattern.MatchString(a.OrganisationID) {
+		v.add("organisation_id", "must be a UUID")
+	}
+	if a.Type != "" && !a.Type.IsValid() {
+		v.add("type", `must be "accounts"`)
+	}
+
+	attrs := a.Attributes
+	if attrs.BaseCurrency != "" && !attrs.BaseCurrency.IsValid() {
+		v.add("attributes.base_currency", "must be an ISO 4217 currency code")
+	}
+	if attrs.BIC != "" && !bicPattern.MatchString(attrs.BIC) {
+		v.add("attributes.bic", "must be a BIC of 8 or 11 characters")
+	}
+
+	switch {
+	case attrs.Country == "":
+		v.add("attributes.country", "is required")
+	case !attrs.Country.IsValid():
+		v.add("attributes.country", "must be an ISO 3166-1 alpha-2 country code")
+	default:
+		if rule, ok := countryRules[attrs.Country]; ok {
+			v.checkCountryRule(attrs, rule)
+		}
+	}
+
+	if attrs.IBAN != "" && !v.has("attributes.country") && !v.has("attributes.iban") {
+		v.checkIBAN(attrs)
+	}
+
+	return v.err()
+}
+
+// validator accumulates the field errors found while validating an account.
+type validator struct {
+	fields []FieldError
+}
+
+func (v *validator) add(field, msg string) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: msg})
+}
+
+// has reports whether an error was already found in the given field.
+func (v *validator) has(field string) bool {
+	for _, f := range v.fields {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// err returns a ValidationError with the accumulated field errors, if any.
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
+
+func (v *validator) checkCountryRule(attrs Attributes, rule countryRule) {
+	country := attrs.Country
+
+	switch {
+	case rule.bankID == nil && attrs.BankID != "":
+		v.add("attributes.bank_id", fmt.Sprintf("not supported for country %s", country))
+	case attrs.BankID == "" && rule.bankIDRequired:
+		v.add("attributes.bank_id", fmt.Sprintf("is required for country %s", country))
+	case attrs.BankID != "" && !rule.bankID.MatchString(attrs.BankID):
+		v.add("attributes.bank_id", fmt.Sprintf("invalid format for country %s", country))
+	}
+
+	switch {
+	case rule.bankIDCode == "" && attrs.BankIDCode != "":
+		v.add("attributes.bank_id_code", fmt.Sprintf("not supported for country %s", country))
+	case rule.bankIDCode != "" && attrs.BankIDCode != rule.bankIDCode:
+		v.add("attributes.bank_id_code", fmt.Sprintf("must be %s for country %s", rule.bankIDCode, country))
+	}
+
+	if rule.bicRequired && attrs.BIC == "" {
+		v.add("attributes.bic", fmt.Sprintf("is required for country %s", country))
+	}
+
+	if attrs.AccountNumber != "" && !rule.accountNumber.MatchString(attrs.AccountNumber) {
+		v.add("attributes.account_number", fmt.Sprintf("invalid format for country %s", country))
+	}
+
+	if !rule.ibanAllowed && attrs.IBAN != "" {
+		v.add("attributes.iban", fmt.Sprintf("not supported for country %s", country))
+	}
+}
+
+// checkIBAN checks the IBAN of the given attributes attrs, and that its components agree with the other attributes.
+// The IBANs of the countries unknown to the iban package are only checked against the country.
+func (v *validator) checkIBAN(attrs Attributes) {
+	parsed, err := iban.Parse(attrs.IBAN)
+	if errors.Is(err, iban.ErrUnsupportedCountry) {
+		if Country(iban.Normalize(attrs.IBAN)[:2]) != attrs.Country {
+			v.add("attributes.iban", "does not match country")
+		}
+		return
+	}
+	if err != nil {
+		v.add("attributes.iban", err.Error())
+		return
+	}
+
+	if Country(parsed.Country) != attrs.Country {
+		v.add("attributes.iban", "does not match country")
+		return
+	}
+	if parsed.BankID != "" && attrs.BankID != "" && parsed.BankID != attrs.BankID {
+		v.add("attributes.iban", "does not match bank_id")
+	}
+	if parsed.AccountNumber != "" && attrs.AccountNumber != "" &&
+		strings.TrimLeft(parsed.AccountNumber, "0") != strings.TrimLeft(attrs.AccountNumber, "0") {
+		v.add("attributes.iban", "does not match account_number")
+	}
+	if parsed.BankCode != "" && len(attrs.BIC) >= 4 && parsed.BankCode != attrs.BIC[:4] {
+		v.add("attributes.iban", "does not match bic")
+	}
+}
@@ -0,0 +1,99 @@
+// +build unit
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	type testData struct {
+		account Account
+		fields  []string
+	}
+
+	withAttrs := func(f func(a *Attributes)) Account {
+		a := accountOne
+		f(&a.Attributes)
+		return a
+	}
+
+	var golds = []testData{
+		0: {accountOne, nil},
+		1: {Account{}, []string{"id", "organisation_id", "attributes.country"}},
+		2: {withAttrs(func(a *Attributes) { a.Country = "XX" }), []string{"attributes.country"}},
+		3: {withAttrs(func(a *Attributes) { a.BaseCurrency = "GBX" }), []string{"attributes.base_currency"}},
+		4: {withAttrs(func(a *Attributes) { a.BIC = "NWBK" }), []string{"attributes.bic"}},
+		5: {withAttrs(func(a *Attributes) { a.BankID = "40030" }), []string{"attributes.bank_id", "attributes.iban"}},
+		6: {withAttrs(func(a *Attributes) { a.BankIDCode = "DEBLZ" }), []string{"attributes.bank_id_code"}},
+		7: {withAttrs(func(a *Attributes) { a.AccountNumber = "4142681" }), []string{"attributes.account_number", "attributes.iban"}},
+		8: {withAttrs(func(a *Attributes) { a.BankID, a.BIC = "", "" }), []string{"attributes.bank_id", "attributes.bic"}},
+		9: {withAttrs(func(a *Attributes) {
+			a.Country, a.BaseCurrency, a.BankID, a.BankIDCode, a.AccountNumber, a.BIC, a.IBAN = "DE", "EUR", "37040044", "DEBLZ", "0532013000", "", "DE89370400440532013000"
+		}), nil},
+		10: {withAttrs(func(a *Attributes) { a.Country, a.BankID, a.BankIDCode, a.IBAN = "NL", "", "", "" }), []string{"attributes.account_number"}},
+		11: {withAttrs(func(a *Attributes) {
+			a.Country, a.BankID, a.BankIDCode, a.AccountNumber = "US", "021000021", "USABA", "123456789"
+		}), []string{"attributes.iban"}},
+		12: {withAttrs(func(a *Attributes) {
+			a.Country, a.BankID, a.BankIDCode, a.AccountNumber = "FR", "2004101005", "", "0500013M026"
+		}), []string{"attributes.bank_id_code", "attributes.account_number", "attributes.iban"}},
+		13: {withAttrs(func(a *Attributes) { a.IBAN = "GB11NWBK40030041426819" }), []string{"attributes.iban"}},
+		14: {withAttrs(func(a *Attributes) { a.IBAN = "GB60NWBK40030141426819" }), []string{"attributes.iban"}},
+		15: {withAttrs(func(a *Attributes) { a.IBAN = "GB43NWBK40030041426818" }), []string{"attributes.iban"}},
+		16: {withAttrs(func(a *Attributes) { a.IBAN = "GB88BARC40030041426819" }), []string{"attributes.iban"}},
+		// The IBANs of the countries unknown to the iban package are accepted
+		17: {withAttrs(func(a *Attributes) { a.Country, a.IBAN = "HU", "HU42117730161111101800000000" }), nil},
+		18: {withAttrs(func(a *Attributes) { a.Country, a.IBAN = "HU", "PL61109010140000071219812874" }), []string{"attributes.iban"}},
+	}
+
+	for i, g := range golds {
+		err := g.account.Validate()
+
+		if g.fields == nil {
+			assert.NoError(t, err, fmt.Sprintf("%d. Unexpected error", i))
+			continue
+		}
+
+		var vErr *ValidationError
+		assert.True(t, errors.As(err, &vErr), fmt.Sprintf("%d. Want a ValidationError, but got %+v", i, err))
+		assert.True(t, errors.Is(err, ErrInvalidAccount), fmt.Sprintf("%d. Want error %+v, but got %+v", i, ErrInvalidAccount, err))
+
+		var fields []string
+		for _, f := range vErr.Fields {
+			fields = append(fields, f.Field)
+		}
+		assert.Equal(t, g.fields, fields, fmt.Sprintf("%d. Unexpected invalid fields in %s", i, err))
+	}
+}
+
+func TestCreateWithValidation(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+		serveError(t, rw, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, WithValidation())
+	assert.NoError(t, err)
+
+	_, err = c.Create(&AccountResource{Data: createBadAccount()})
+
+	assert.True(t, errors.Is(err, ErrInvalidAccount), fmt.Sprintf("Want error %+v, but got %+v", ErrInvalidAccount, err))
+	assert.Equal(t, 0, calls, "Want no request sent for an invalid account")
+
+	// Without validation, the invalid account is sent and rejected by the service
+	c, err = New(server.URL)
+	assert.NoError(t, err)
+
+	_, err = c.Create(&AccountResource{Data: createBadAccount()})
+
+	assert.True(t, errors.Is(err, ErrBadInput), fmt.Sprintf("Want error %+v, but got %+v", ErrBadInput, err))
+	assert.Equal(t, 1, calls)
+}